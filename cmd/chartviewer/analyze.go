@@ -0,0 +1,122 @@
+package chartviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+func NewAnalyzeCommand() *cobra.Command {
+	var (
+		apiVersionsPath  string
+		deprecationsPath string
+		kubeVersion      string
+		format           string
+		outputPath       string
+	)
+
+	command := cobra.Command{
+		Use:   "analyze CHART",
+		Short: "Check the API versions of a local chart against a Kubernetes version",
+		Example: "chart-viewer analyze ./charts/app --kube-version 1.22\n" +
+			"chart-viewer analyze ./charts/app --format sarif --output chart-viewer.sarif\n" +
+			"chart-viewer analyze ./charts/app --format junit --output chart-viewer.xml",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !report.IsFormat(format) {
+				return fmt.Errorf("unsupported format %q, use json, sarif or junit", format)
+			}
+
+			var dataset []model.KubernetesAPIVersion
+			err := readJSONFile(apiVersionsPath, &dataset)
+			if err != nil {
+				return err
+			}
+
+			kubeAPIVersion, err := kubeversion.Find(dataset, kubeVersion)
+			if err != nil {
+				return err
+			}
+
+			var deprecations []model.APIDeprecation
+			if deprecationsPath != "" {
+				err = readJSONFile(deprecationsPath, &deprecations)
+				if err != nil {
+					return err
+				}
+			}
+
+			chartPath := args[0]
+			templates, err := helm.GetLocalTemplates(chartPath)
+			if err != nil {
+				return fmt.Errorf("cannot load chart %s: %w", chartPath, err)
+			}
+
+			results, err := analyzer.New().Analyze(templates, kubeAPIVersion, deprecations)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputPath != "" {
+				file, err := os.Create(outputPath)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+
+			return writeAnalysis(out, format, chartPath, kubeAPIVersion.KubeVersion, results)
+		},
+	}
+
+	command.Flags().StringVar(&apiVersionsPath, "api-versions", "./api_versions.json", "Path to the JSON file that contain list of Kubernetes API version for each Kubernetes version")
+	command.Flags().StringVar(&deprecationsPath, "api-deprecations", "./api_deprecations.json", "Path to the JSON file that contain the Kubernetes version where each API is deprecated and removed")
+	command.Flags().StringVar(&kubeVersion, "kube-version", "", "Kubernetes version to check against, default to the latest version of the dataset")
+	command.Flags().StringVar(&format, "format", report.FormatJSON, "Output format: json, sarif or junit")
+	command.Flags().StringVar(&outputPath, "output", "", "Path to write the result to, default to the standard output")
+	return &command
+}
+
+// writeAnalysis writes the results in the given format. SARIF and JUnit
+// locations are relative to the working directory when the chart is a
+// directory, so code scanning tools can map them to the repository files.
+func writeAnalysis(w io.Writer, format, chartPath, kubeVersion string, results []model.AnalyticsResult) error {
+	if format == report.FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(results)
+	}
+
+	root := ""
+	if info, err := os.Stat(chartPath); err == nil && info.IsDir() {
+		root = filepath.ToSlash(filepath.Clean(chartPath))
+	}
+
+	name := fmt.Sprintf("%s on Kubernetes %s", filepath.Base(chartPath), kubeVersion)
+	return report.Write(w, format, name, report.FromTemplates(root, results))
+}
+
+func readJSONFile(path string, v interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(content, v)
+	if err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	return nil
+}
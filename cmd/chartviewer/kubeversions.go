@@ -0,0 +1,113 @@
+package chartviewer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func NewKubeVersionsCommand() *cobra.Command {
+	command := cobra.Command{
+		Use:   "kube-versions",
+		Short: "Manage the Kubernetes API version dataset",
+		Run: func(c *cobra.Command, args []string) {
+			c.HelpFunc()(c, args)
+		},
+	}
+
+	command.AddCommand(newKubeVersionsImportCommand())
+	return &command
+}
+
+func newKubeVersionsImportCommand() *cobra.Command {
+	var (
+		datasetPath string
+		outputPath  string
+		kubeVersion string
+		format      string
+		replace     bool
+	)
+
+	command := cobra.Command{
+		Use:   "import FILE...",
+		Short: "Import API versions from saved kubectl api-resources output, discovery or OpenAPI documents",
+		Example: "kubectl api-resources > api-resources.txt\n" +
+			"chart-viewer kube-versions import --kube-version 1.27 api-resources.txt\n" +
+			"chart-viewer kube-versions import swagger.json",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var dataset []model.KubernetesAPIVersion
+			content, err := os.ReadFile(datasetPath)
+			if err == nil {
+				err = json.Unmarshal(content, &dataset)
+				if err != nil {
+					return fmt.Errorf("cannot parse %s: %w", datasetPath, err)
+				}
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			imported := map[string][]model.GroupVersionKind{}
+			var importedVersions []string
+			for _, path := range args {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+
+				document, err := kubeversion.Parse(content, format)
+				if err != nil {
+					return fmt.Errorf("cannot import %s: %w", path, err)
+				}
+
+				version := kubeVersion
+				if version == "" {
+					version = document.KubeVersion
+				}
+				if version == "" {
+					return fmt.Errorf("cannot find the Kubernetes version of %s, set it with --kube-version", path)
+				}
+
+				if _, ok := imported[version]; !ok {
+					importedVersions = append(importedVersions, version)
+				}
+				imported[version] = append(imported[version], document.Resources...)
+				log.Printf("read %d resources of Kubernetes %s from %s\n", len(document.Resources), version, path)
+			}
+
+			for _, version := range importedVersions {
+				dataset = kubeversion.Merge(dataset, version, imported[version], replace)
+			}
+
+			output, err := json.MarshalIndent(dataset, "", "    ")
+			if err != nil {
+				return err
+			}
+
+			if outputPath == "" {
+				outputPath = datasetPath
+			}
+
+			err = os.WriteFile(outputPath, append(output, '\n'), 0644)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("dataset written to %s, run seed to load it\n", outputPath)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&datasetPath, "dataset", "./api_versions.json", "Path to the JSON file that contain list of Kubernetes API version for each Kubernetes version")
+	command.Flags().StringVar(&outputPath, "output", "", "Path to write the merged dataset to, default to the dataset file")
+	command.Flags().StringVar(&kubeVersion, "kube-version", "", "Kubernetes version of the imported files, read from OpenAPI documents when not set")
+	command.Flags().StringVar(&format, "format", kubeversion.FormatAuto, "Format of the imported files: auto, api-resources, discovery or openapi")
+	command.Flags().BoolVar(&replace, "replace", false, "Replace the record of the Kubernetes version instead of merging into it")
+	return &command
+}
@@ -16,6 +16,9 @@ func NewRootCommand() *cobra.Command {
 	command.AddCommand(
 		NewServeCommand(),
 		NewSeedCommand(),
+		NewKubeVersionsCommand(),
+		NewAnalyzeCommand(),
+		NewUpgradeImpactCommand(),
 	)
 
 	return command
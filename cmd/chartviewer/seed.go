@@ -1,11 +1,14 @@
 package chartviewer
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"sync"
+	"time"
 
+	"chart-viewer/pkg/analyzer"
 	"chart-viewer/pkg/helm"
 	"chart-viewer/pkg/model"
 	"chart-viewer/pkg/repository"
@@ -17,27 +20,59 @@ import (
 type Service interface {
 	GetRepos() ([]model.Repo, error)
 	GetCharts(repoName string) ([]model.Chart, error)
+	GetChangelog(repoName, chartName, fromVersion, toVersion string) (model.Changelog, error)
 	GetValues(repoName, chartName, chartVersion string) (map[string]interface{}, error)
 	GetTemplates(repoName, chartName, chartVersion string) ([]model.Template, error)
-	RenderManifest(repoName, chartName, chartVersion string, valuesFileLocation string) (model.ManifestResponse, error)
-	GetStringifiedManifests(repoName, chartName, chartVersion, hash string) (string, error)
+	RenderManifest(repoName, chartName, chartVersion, releaseName string, values string, options model.RenderOptions) (model.ManifestResponse, error)
+	RenderBatch(releases []model.BatchRelease) (model.BatchRender, error)
+	GetStringifiedManifests(repoName, chartName, chartVersion, hash string, withNotes bool) (string, error)
+	GetRenderedManifests(repoName, chartName, chartVersion, hash string) (model.ManifestResponse, error)
+	GetRenderValues(repoName, chartName, chartVersion, hash string) (map[string]interface{}, error)
 	GetChart(repoName string, chartName string, chartVersion string) (model.ChartDetail, error)
 	AnalyzeTemplate(templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error)
+	AnalyzeManifest(repoName, chartName, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestAnalyticsResult, error)
+	ValidateManifest(repoName, chartName, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestValidationResult, error)
+	GetChartReport(repoName, chartName, chartVersion string, values string, options model.RenderOptions) (model.ChartReport, error)
+	GetCompatibilityMatrix(repoName, chartName string) (model.CompatibilityMatrix, error)
+	DiffValues(repoName, chartName, fromVersion, toVersion string) (model.ValuesDiff, error)
+	DiffTemplates(repoName, chartName, fromVersion, toVersion string) (model.TemplatesDiff, error)
+	DiffManifests(repoName, chartName string, from, to model.RenderSpec) (model.ManifestDiff, error)
+	GetUpgradeImpact(repoName, chartName, fromVersion, toVersion string, values string, options model.RenderOptions) (model.UpgradeImpact, error)
+	GetImageInventory(repoName, chartName, chartVersion string, values string, options model.RenderOptions) (model.ImageInventory, error)
+	CompareImages(repoName, chartName, fromVersion, toVersion string, values string, options model.RenderOptions) (model.ImageComparison, error)
+	GetPolicies() ([]model.Policy, error)
+	SavePolicy(policy model.Policy) error
+	DeletePolicy(id string) error
+	CreateSnapshot(repoName, chartName, chartVersion string, req model.SnapshotRequest) (model.Snapshot, error)
+	RerenderSnapshot(id string, req model.SnapshotRerenderRequest) (model.Snapshot, error)
+	GetSnapshots() ([]model.Snapshot, error)
+	GetSnapshot(id string) (model.Snapshot, error)
+	GetStringifiedSnapshot(id string, withNotes bool) (string, error)
+	DeleteSnapshot(id string) error
 }
 
 type Repository interface {
 	Set(string, string) error
+	SetWithExpiration(string, string, time.Duration) error
 	Get(string) (string, error)
+	Delete(string) error
+	Exists(string) (bool, error)
+	HSet(string, string, string) error
+	HGetAll(string) (map[string]string, error)
+	HDel(string, string) error
 }
 
 var wg = &sync.WaitGroup{}
 
 func NewSeedCommand() *cobra.Command {
 	var (
-		redisHost          string
-		redisPort          string
-		repoSeedPath       string
-		apiVersionSeedPath string
+		redisHost           string
+		redisPort           string
+		repoSeedPath        string
+		apiVersionSeedPath  string
+		deprecationSeedPath string
+		policyDir           string
+		chartCacheDir       string
 	)
 
 	command := cobra.Command{
@@ -68,13 +103,29 @@ func NewSeedCommand() *cobra.Command {
 			}
 			log.Println("Kubernetes API version seeded")
 
+			err = seedAPIDeprecation(repo, deprecationSeedPath)
+			if err != nil {
+				log.Printf("failed to seed api deprecation: %s\n", err)
+			}
+			log.Println("Kubernetes API deprecation seeded")
+
+			err = seedPolicies(repo, policyDir)
+			if err != nil {
+				log.Printf("failed to seed policies: %s\n", err)
+			}
+
+			err = migrateSnapshots(repo)
+			if err != nil {
+				log.Printf("failed to migrate snapshots: %s\n", err)
+			}
+
 			err = seedRepo(repo, repoSeedPath)
 			if err != nil {
 				log.Printf("failed to seed chart repository: %s\n", err)
 				return err
 			}
 
-			err = seedChart(repo)
+			err = seedChart(repo, chartCacheDir)
 			if err != nil {
 				log.Printf("failed to seed chart: %s\n", err)
 			}
@@ -88,6 +139,9 @@ func NewSeedCommand() *cobra.Command {
 	command.Flags().StringVar(&redisPort, "redis-port", "6379", "Redis host port")
 	command.Flags().StringVar(&repoSeedPath, "repo-seed", "./seed.json", "Path to JSON file that contain array of repositories.")
 	command.Flags().StringVar(&apiVersionSeedPath, "kube-version-seed", "./api_versions.json", "Path to JSON file that contain list of Kubernetes API version for each Kubernetes version")
+	command.Flags().StringVar(&deprecationSeedPath, "api-deprecation-seed", "./api_deprecations.json", "Path to JSON file that contain the Kubernetes version where each API is deprecated and removed")
+	command.Flags().StringVar(&policyDir, "policy-dir", "", "Path to directory that contain analyzer policy files, added to the policies managed through the API")
+	command.Flags().StringVar(&chartCacheDir, "chart-cache-dir", "", "Directory to store downloaded chart archives, default to the helm repository cache")
 	return &command
 }
 
@@ -102,6 +156,97 @@ func seedKubeVersion(repo Repository, path string) error {
 	return nil
 }
 
+func seedAPIDeprecation(repo Repository, path string) error {
+	deprecations, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var parsed []model.APIDeprecation
+	err = json.Unmarshal(deprecations, &parsed)
+	if err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	return repo.Set("api-deprecations", string(deprecations))
+}
+
+func seedPolicies(repo Repository, dir string) error {
+	var policies []model.Policy
+	stored, err := repo.Get("policies")
+	if err == nil && stored != "" {
+		err = json.Unmarshal([]byte(stored), &policies)
+		if err != nil {
+			return err
+		}
+	}
+
+	if dir != "" {
+		loaded, err := analyzer.LoadPolicies(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, policy := range loaded {
+			replaced := false
+			for i, p := range policies {
+				if p.ID == policy.ID {
+					policies[i] = policy
+					replaced = true
+				}
+			}
+
+			if !replaced {
+				policies = append(policies, policy)
+			}
+		}
+		log.Printf("%d policies loaded from %s\n", len(loaded), dir)
+	}
+
+	if policies == nil {
+		policies = []model.Policy{}
+	}
+
+	policiesByte, err := json.Marshal(policies)
+	if err != nil {
+		return err
+	}
+
+	return repo.Set("policies", string(policiesByte))
+}
+
+// migrateSnapshots moves the snapshot summaries of the legacy "snapshots"
+// JSON list to the "snapshot-index" hash the service reads, and deletes the
+// list.
+func migrateSnapshots(repo Repository) error {
+	stored, err := repo.Get("snapshots")
+	if err != nil || stored == "" {
+		return err
+	}
+
+	var snapshots []model.Snapshot
+	err = json.Unmarshal([]byte(stored), &snapshots)
+	if err != nil {
+		log.Printf("deleting the legacy snapshot list, cannot parse it: %s\n", err)
+		return repo.Delete("snapshots")
+	}
+
+	for _, snapshot := range snapshots {
+		summaryByte, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+
+		err = repo.HSet("snapshot-index", snapshot.ID, string(summaryByte))
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("%d snapshots migrated to the snapshot index\n", len(snapshots))
+
+	return repo.Delete("snapshots")
+}
+
 func seedRepo(repo Repository, seedPath string) error {
 	repos, err := os.ReadFile(seedPath)
 	if err != nil {
@@ -114,9 +259,9 @@ func seedRepo(repo Repository, seedPath string) error {
 	return nil
 }
 
-func seedChart(repo Repository) error {
-	h := helm.NewHelmClient(repo)
-	svc := service.NewService(h, repo, nil, nil)
+func seedChart(repo Repository, chartCacheDir string) error {
+	h := helm.NewHelmClient(repo, chartCacheDir)
+	svc := service.NewService(h, repo, nil, nil, nil)
 
 	chartRepos, err := svc.GetRepos()
 	if err != nil {
@@ -0,0 +1,22 @@
+package chartviewer
+
+import (
+	"testing"
+
+	"chart-viewer/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_migrateSnapshots(t *testing.T) {
+	repo := new(mocks.Repository)
+	repo.On("Get", "snapshots").Return(`[{"id":"0123456789abcdef","name":"incident 42","url":"/api/v1/snapshots/0123456789abcdef","created_at":"2023-01-01T00:00:00Z"}]`, nil).Once()
+	repo.On("HSet", "snapshot-index", "0123456789abcdef", `{"id":"0123456789abcdef","name":"incident 42","repo":"","chart":"","version":"","values":"","options":{},"url":"/api/v1/snapshots/0123456789abcdef","created_at":"2023-01-01T00:00:00Z"}`).Return(nil)
+	repo.On("Delete", "snapshots").Return(nil)
+
+	assert.NoError(t, migrateSnapshots(repo))
+	repo.AssertExpectations(t)
+
+	repo.On("Get", "snapshots").Return("", nil)
+	assert.NoError(t, migrateSnapshots(repo))
+	repo.AssertNumberOfCalls(t, "Delete", 1)
+}
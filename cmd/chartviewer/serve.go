@@ -11,6 +11,7 @@ import (
 	"chart-viewer/pkg/rest"
 	"chart-viewer/pkg/server/handler"
 	"chart-viewer/pkg/server/service"
+	"chart-viewer/pkg/validator"
 	"github.com/go-redis/redis"
 	"github.com/gorilla/mux"
 	"github.com/spf13/cobra"
@@ -22,6 +23,8 @@ func NewServeCommand() *cobra.Command {
 		defaultPort      string
 		defaultRedisHost string
 		defaultRedisPort string
+		chartCacheDir    string
+		schemaDir        string
 	)
 
 	command := cobra.Command{
@@ -46,10 +49,11 @@ func NewServeCommand() *cobra.Command {
 			}
 
 			repo := repository.NewRepository(redisClient)
-			helmClient := helm.NewHelmClient(repo)
+			helmClient := helm.NewHelmClient(repo, chartCacheDir)
 			analyser := analyzer.New()
 			restClient := rest.New()
-			svc := service.NewService(helmClient, repo, analyser, restClient)
+			schemaValidator := validator.New(schemaDir)
+			svc := service.NewService(helmClient, repo, analyser, restClient, schemaValidator)
 			r := createRouter(svc)
 
 			log.Printf("server run on http://%s\n", address)
@@ -63,6 +67,8 @@ func NewServeCommand() *cobra.Command {
 	command.Flags().StringVar(&defaultPort, "port", "9999", "[Optional] App host port")
 	command.Flags().StringVar(&defaultRedisHost, "redis-host", "127.0.0.1", "[Optional] Redis host address")
 	command.Flags().StringVar(&defaultRedisPort, "redis-port", "6379", "[Optional] Redis host port")
+	command.Flags().StringVar(&chartCacheDir, "chart-cache-dir", "", "[Optional] Directory to store downloaded chart archives, default to the helm repository cache")
+	command.Flags().StringVar(&schemaDir, "schema-dir", "./schemas", "[Optional] Directory that contain the Kubernetes JSON schemas used to validate manifests")
 
 	return &command
 }
@@ -77,11 +83,33 @@ func createRouter(svc Service) *mux.Router {
 	apiV1.Use(appHandler.LoggerMiddleware)
 	apiV1.HandleFunc("/repos", appHandler.GetRepos).Methods("GET")
 	apiV1.HandleFunc("/charts/{repo-name}", appHandler.GetCharts).Methods("GET")
+	apiV1.HandleFunc("/charts/compatibility/{repo-name}/{chart-name}", appHandler.GetCompatibilityMatrix).Methods("GET")
+	apiV1.HandleFunc("/charts/changelog/{repo-name}/{chart-name}", appHandler.GetChangelog).Methods("GET")
 	apiV1.HandleFunc("/charts/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChart).Methods("GET")
 	apiV1.HandleFunc("/charts/values/{repo-name}/{chart-name}/{chart-version}", appHandler.GetValues).Methods("GET")
 	apiV1.HandleFunc("/charts/templates/{repo-name}/{chart-name}/{chart-version}", appHandler.GetTemplates).Methods("GET")
 	apiV1.HandleFunc("/charts/manifests/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifests).Methods("POST", "OPTIONS")
 	apiV1.HandleFunc("/charts/manifests/{repo-name}/{chart-name}/{chart-version}/{hash}", appHandler.GetManifests).Methods("GET")
+	apiV1.HandleFunc("/charts/manifests/{repo-name}/{chart-name}/{chart-version}/{hash}/values", appHandler.GetManifestValues).Methods("GET")
+	apiV1.HandleFunc("/render/batch", appHandler.RenderBatch).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/analyze/{repo-name}/{chart-name}/{chart-version}", appHandler.AnalyzeManifests).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/validate/{repo-name}/{chart-name}/{chart-version}", appHandler.ValidateManifests).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/report/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChartReport).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/diff/values/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.DiffValues).Methods("GET")
+	apiV1.HandleFunc("/charts/diff/templates/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.DiffTemplates).Methods("GET")
+	apiV1.HandleFunc("/charts/diff/manifests/{repo-name}/{chart-name}", appHandler.DiffManifests).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/images/{repo-name}/{chart-name}/{chart-version}", appHandler.GetImageInventory).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/images/compare/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.CompareImages).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/upgrade-impact/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.GetUpgradeImpact).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/snapshots", appHandler.GetSnapshots).Methods("GET")
+	apiV1.HandleFunc("/snapshots/{snapshot-id}", appHandler.GetSnapshot).Methods("GET")
+	apiV1.HandleFunc("/snapshots/{snapshot-id}", appHandler.DeleteSnapshot).Methods("DELETE", "OPTIONS")
+	apiV1.HandleFunc("/snapshots/{snapshot-id}/manifests", appHandler.GetSnapshotManifests).Methods("GET")
+	apiV1.HandleFunc("/snapshots/{snapshot-id}/rerender", appHandler.RerenderSnapshot).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/snapshots/{repo-name}/{chart-name}/{chart-version}", appHandler.CreateSnapshot).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/policies", appHandler.GetPolicies).Methods("GET")
+	apiV1.HandleFunc("/policies/{policy-id}", appHandler.SavePolicy).Methods("PUT", "OPTIONS")
+	apiV1.HandleFunc("/policies/{policy-id}", appHandler.DeletePolicy).Methods("DELETE", "OPTIONS")
 
 	fileServer := http.FileServer(http.Dir("ui/dist"))
 	r.PathPrefix("/js").Handler(http.StripPrefix("/", fileServer))
@@ -0,0 +1,59 @@
+package chartviewer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chart-viewer/mocks"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_createRouter(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedResult string
+		mockFn         func(svc *mocks.Service)
+	}{
+		{
+			name:           "should route the compatibility matrix before the chart",
+			path:           "/api/v1/charts/compatibility/stable/app",
+			expectedResult: `{"error":"cannot get compatibility matrix of stable/app: error"}`,
+			mockFn: func(svc *mocks.Service) {
+				svc.On("GetCompatibilityMatrix", "stable", "app").Return(model.CompatibilityMatrix{}, errors.New("error"))
+			},
+		},
+		{
+			name:           "should route the changelog before the chart",
+			path:           "/api/v1/charts/changelog/stable/app",
+			expectedResult: `{"error":"cannot get changelog of stable/app: error"}`,
+			mockFn: func(svc *mocks.Service) {
+				svc.On("GetChangelog", "stable", "app", "", "").Return(model.Changelog{}, errors.New("error"))
+			},
+		},
+		{
+			name:           "should route the chart",
+			path:           "/api/v1/charts/stable/app/1.0.0",
+			expectedResult: `{"error":"error when get chart stable/app:1.0.0: error"}`,
+			mockFn: func(svc *mocks.Service) {
+				svc.On("GetChart", "stable", "app", "1.0.0").Return(model.ChartDetail{}, errors.New("error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(mocks.Service)
+			tt.mockFn(svc)
+
+			recorder := httptest.NewRecorder()
+			createRouter(svc).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			assert.JSONEq(t, tt.expectedResult, recorder.Body.String())
+			svc.AssertExpectations(t)
+		})
+	}
+}
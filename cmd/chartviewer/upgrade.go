@@ -0,0 +1,129 @@
+package chartviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"chart-viewer/pkg/server/service"
+	"github.com/go-redis/redis"
+	"github.com/spf13/cobra"
+)
+
+func NewUpgradeImpactCommand() *cobra.Command {
+	var (
+		redisHost     string
+		redisPort     string
+		chartCacheDir string
+		valuesPath    string
+		format        string
+		outputPath    string
+	)
+
+	command := cobra.Command{
+		Use:   "upgrade-impact REPO/CHART FROM_VERSION TO_VERSION",
+		Short: "Report what needs attention when upgrading a chart from one version to another",
+		Example: "chart-viewer upgrade-impact stable/app 1.0.0 1.1.0 --values values.yaml\n" +
+			"chart-viewer upgrade-impact stable/app 1.0.0 1.1.0 --values values.yaml --format json --output impact.json",
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported format %q, use text or json", format)
+			}
+
+			repoName, chartName, ok := strings.Cut(args[0], "/")
+			if !ok {
+				return fmt.Errorf("invalid chart %q, use REPO/CHART", args[0])
+			}
+
+			var values []byte
+			if valuesPath != "" {
+				var err error
+				values, err = os.ReadFile(valuesPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			redisClient := redis.NewClient(&redis.Options{Addr: fmt.Sprintf("%s:%s", redisHost, redisPort)})
+			err := redisClient.Ping().Err()
+			if err != nil {
+				return fmt.Errorf("cannot connect to redis: %w", err)
+			}
+
+			repo := repository.NewRepository(redisClient)
+			svc := service.NewService(helm.NewHelmClient(repo, chartCacheDir), repo, analyzer.New(), nil, nil)
+			impact, err := svc.GetUpgradeImpact(repoName, chartName, args[1], args[2], string(values), model.RenderOptions{})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputPath != "" {
+				file, err := os.Create(outputPath)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+
+			if format == "json" {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "    ")
+				return encoder.Encode(impact)
+			}
+
+			return writeUpgradeImpact(out, impact)
+		},
+	}
+
+	command.Flags().StringVar(&redisHost, "redis-host", "127.0.0.1", "Redis host address")
+	command.Flags().StringVar(&redisPort, "redis-port", "6379", "Redis host port")
+	command.Flags().StringVar(&chartCacheDir, "chart-cache-dir", "", "Directory to store downloaded chart archives, default to the helm repository cache")
+	command.Flags().StringVarP(&valuesPath, "values", "f", "", "Path to the values file used with the chart")
+	command.Flags().StringVar(&format, "format", "text", "Output format: text or json")
+	command.Flags().StringVar(&outputPath, "output", "", "Path to write the result to, default to the standard output")
+	return &command
+}
+
+func writeUpgradeImpact(w io.Writer, impact model.UpgradeImpact) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrading %s/%s from %s to %s\n", impact.Repo, impact.Chart, impact.From, impact.To)
+
+	fmt.Fprintf(&b, "\nValues set but removed from the chart: %d\n", len(impact.Values))
+	for _, v := range impact.Values {
+		if v.RenamedTo != "" {
+			fmt.Fprintf(&b, "  %s, likely moved to %s\n", v.Path, v.RenamedTo)
+		} else {
+			fmt.Fprintf(&b, "  %s\n", v.Path)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nObjects to delete and create again: %d\n", len(impact.Recreated))
+	for _, r := range impact.Recreated {
+		fmt.Fprintf(&b, "  %s (%s): %s\n", r.ObjectIdentity, r.Manifest, strings.Join(r.Fields, ", "))
+	}
+
+	fmt.Fprintf(&b, "\nRemoved objects: %d\n", len(impact.RemovedResources))
+	for _, r := range impact.RemovedResources {
+		fmt.Fprintf(&b, "  %s\n", r)
+	}
+
+	fmt.Fprintf(&b, "\nImages: %d changed, %d added\n", len(impact.ImageChanges), len(impact.AddedImages))
+	for _, c := range impact.ImageChanges {
+		fmt.Fprintf(&b, "  %s -> %s\n", c.From.Image, c.To.Image)
+	}
+	for _, image := range impact.AddedImages {
+		fmt.Fprintf(&b, "  + %s\n", image.Image)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -13,25 +13,131 @@ type Analytic struct {
 	mock.Mock
 }
 
-// Analyze provides a mock function with given fields: templates, kubeAPIVersions
-func (_m *Analytic) Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error) {
-	ret := _m.Called(templates, kubeAPIVersions)
+// Analyze provides a mock function with given fields: templates, kubeAPIVersions, deprecations
+func (_m *Analytic) Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion, deprecations []model.APIDeprecation) ([]model.AnalyticsResult, error) {
+	ret := _m.Called(templates, kubeAPIVersions, deprecations)
 
 	var r0 []model.AnalyticsResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func([]model.Template, model.KubernetesAPIVersion) ([]model.AnalyticsResult, error)); ok {
-		return rf(templates, kubeAPIVersions)
+	if rf, ok := ret.Get(0).(func([]model.Template, model.KubernetesAPIVersion, []model.APIDeprecation) ([]model.AnalyticsResult, error)); ok {
+		return rf(templates, kubeAPIVersions, deprecations)
 	}
-	if rf, ok := ret.Get(0).(func([]model.Template, model.KubernetesAPIVersion) []model.AnalyticsResult); ok {
-		r0 = rf(templates, kubeAPIVersions)
+	if rf, ok := ret.Get(0).(func([]model.Template, model.KubernetesAPIVersion, []model.APIDeprecation) []model.AnalyticsResult); ok {
+		r0 = rf(templates, kubeAPIVersions, deprecations)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.AnalyticsResult)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func([]model.Template, model.KubernetesAPIVersion) error); ok {
-		r1 = rf(templates, kubeAPIVersions)
+	if rf, ok := ret.Get(1).(func([]model.Template, model.KubernetesAPIVersion, []model.APIDeprecation) error); ok {
+		r1 = rf(templates, kubeAPIVersions, deprecations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AnalyzeManifests provides a mock function with given fields: manifests, kubeAPIVersions, deprecations
+func (_m *Analytic) AnalyzeManifests(manifests []model.Manifest, kubeAPIVersions model.KubernetesAPIVersion, deprecations []model.APIDeprecation) ([]model.ManifestAnalyticsResult, error) {
+	ret := _m.Called(manifests, kubeAPIVersions, deprecations)
+
+	var r0 []model.ManifestAnalyticsResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]model.Manifest, model.KubernetesAPIVersion, []model.APIDeprecation) ([]model.ManifestAnalyticsResult, error)); ok {
+		return rf(manifests, kubeAPIVersions, deprecations)
+	}
+	if rf, ok := ret.Get(0).(func([]model.Manifest, model.KubernetesAPIVersion, []model.APIDeprecation) []model.ManifestAnalyticsResult); ok {
+		r0 = rf(manifests, kubeAPIVersions, deprecations)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ManifestAnalyticsResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]model.Manifest, model.KubernetesAPIVersion, []model.APIDeprecation) error); ok {
+		r1 = rf(manifests, kubeAPIVersions, deprecations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Check provides a mock function with given fields: manifests, policies
+func (_m *Analytic) Check(manifests []model.Manifest, policies []model.Policy) ([]model.RuleFinding, error) {
+	ret := _m.Called(manifests, policies)
+
+	var r0 []model.RuleFinding
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]model.Manifest, []model.Policy) ([]model.RuleFinding, error)); ok {
+		return rf(manifests, policies)
+	}
+	if rf, ok := ret.Get(0).(func([]model.Manifest, []model.Policy) []model.RuleFinding); ok {
+		r0 = rf(manifests, policies)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.RuleFinding)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]model.Manifest, []model.Policy) error); ok {
+		r1 = rf(manifests, policies)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompareImages provides a mock function with given fields: from, to
+func (_m *Analytic) CompareImages(from []model.ContainerImage, to []model.ContainerImage) model.ImageComparison {
+	ret := _m.Called(from, to)
+
+	var r0 model.ImageComparison
+	if rf, ok := ret.Get(0).(func([]model.ContainerImage, []model.ContainerImage) model.ImageComparison); ok {
+		r0 = rf(from, to)
+	} else {
+		r0 = ret.Get(0).(model.ImageComparison)
+	}
+
+	return r0
+}
+
+// CompilePolicy provides a mock function with given fields: policy
+func (_m *Analytic) CompilePolicy(policy model.Policy) error {
+	ret := _m.Called(policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.Policy) error); ok {
+		r0 = rf(policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Images provides a mock function with given fields: manifests
+func (_m *Analytic) Images(manifests []model.Manifest) ([]model.ContainerImage, error) {
+	ret := _m.Called(manifests)
+
+	var r0 []model.ContainerImage
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]model.Manifest) ([]model.ContainerImage, error)); ok {
+		return rf(manifests)
+	}
+	if rf, ok := ret.Get(0).(func([]model.Manifest) []model.ContainerImage); ok {
+		r0 = rf(manifests)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ContainerImage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]model.Manifest) error); ok {
+		r1 = rf(manifests)
 	} else {
 		r1 = ret.Error(1)
 	}
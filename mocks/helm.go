@@ -65,25 +65,23 @@ func (_m *Helm) GetValues(chartUrl string, chartName string, chartVersion string
 	return r0, r1
 }
 
-// RenderManifest provides a mock function with given fields: chartUrl, chartName, chartVersion, valuesFileLocation
-func (_m *Helm) RenderManifest(chartUrl string, chartName string, chartVersion string, valuesFileLocation string) ([]model.Manifest, error) {
-	ret := _m.Called(chartUrl, chartName, chartVersion, valuesFileLocation)
+// RenderManifest provides a mock function with given fields: chartUrl, chartName, chartVersion, releaseName, values, options, kubeAPIVersion
+func (_m *Helm) RenderManifest(chartUrl string, chartName string, chartVersion string, releaseName string, values map[string]interface{}, options model.RenderOptions, kubeAPIVersion *model.KubernetesAPIVersion) (model.RenderResult, error) {
+	ret := _m.Called(chartUrl, chartName, chartVersion, releaseName, values, options, kubeAPIVersion)
 
-	var r0 []model.Manifest
+	var r0 model.RenderResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string) ([]model.Manifest, error)); ok {
-		return rf(chartUrl, chartName, chartVersion, valuesFileLocation)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, map[string]interface{}, model.RenderOptions, *model.KubernetesAPIVersion) (model.RenderResult, error)); ok {
+		return rf(chartUrl, chartName, chartVersion, releaseName, values, options, kubeAPIVersion)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string, string) []model.Manifest); ok {
-		r0 = rf(chartUrl, chartName, chartVersion, valuesFileLocation)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, map[string]interface{}, model.RenderOptions, *model.KubernetesAPIVersion) model.RenderResult); ok {
+		r0 = rf(chartUrl, chartName, chartVersion, releaseName, values, options, kubeAPIVersion)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Manifest)
-		}
+		r0 = ret.Get(0).(model.RenderResult)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
-		r1 = rf(chartUrl, chartName, chartVersion, valuesFileLocation)
+	if rf, ok := ret.Get(1).(func(string, string, string, string, map[string]interface{}, model.RenderOptions, *model.KubernetesAPIVersion) error); ok {
+		r1 = rf(chartUrl, chartName, chartVersion, releaseName, values, options, kubeAPIVersion)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -2,13 +2,55 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
 
 // Repository is an autogenerated mock type for the Repository type
 type Repository struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: _a0
+func (_m *Repository) Delete(_a0 string) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Exists provides a mock function with given fields: _a0
+func (_m *Repository) Exists(_a0 string) (bool, error) {
+	ret := _m.Called(_a0)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: _a0
 func (_m *Repository) Get(_a0 string) (string, error) {
 	ret := _m.Called(_a0)
@@ -33,6 +75,60 @@ func (_m *Repository) Get(_a0 string) (string, error) {
 	return r0, r1
 }
 
+// HDel provides a mock function with given fields: _a0, _a1
+func (_m *Repository) HDel(_a0 string, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HGetAll provides a mock function with given fields: _a0
+func (_m *Repository) HGetAll(_a0 string) (map[string]string, error) {
+	ret := _m.Called(_a0)
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (map[string]string, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) map[string]string); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HSet provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Repository) HSet(_a0 string, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Set provides a mock function with given fields: _a0, _a1
 func (_m *Repository) Set(_a0 string, _a1 string) error {
 	ret := _m.Called(_a0, _a1)
@@ -47,6 +143,20 @@ func (_m *Repository) Set(_a0 string, _a1 string) error {
 	return r0
 }
 
+// SetWithExpiration provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Repository) SetWithExpiration(_a0 string, _a1 string, _a2 time.Duration) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
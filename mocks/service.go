@@ -13,6 +13,32 @@ type Service struct {
 	mock.Mock
 }
 
+// AnalyzeManifest provides a mock function with given fields: repoName, chartName, chartVersion, values, options, kubeVersion
+func (_m *Service) AnalyzeManifest(repoName string, chartName string, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestAnalyticsResult, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, values, options, kubeVersion)
+
+	var r0 []model.ManifestAnalyticsResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions, string) ([]model.ManifestAnalyticsResult, error)); ok {
+		return rf(repoName, chartName, chartVersion, values, options, kubeVersion)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions, string) []model.ManifestAnalyticsResult); ok {
+		r0 = rf(repoName, chartName, chartVersion, values, options, kubeVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ManifestAnalyticsResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, model.RenderOptions, string) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, values, options, kubeVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AnalyzeTemplate provides a mock function with given fields: templates, kubeVersion
 func (_m *Service) AnalyzeTemplate(templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error) {
 	ret := _m.Called(templates, kubeVersion)
@@ -39,6 +65,178 @@ func (_m *Service) AnalyzeTemplate(templates []model.Template, kubeVersion strin
 	return r0, r1
 }
 
+// CompareImages provides a mock function with given fields: repoName, chartName, fromVersion, toVersion, values, options
+func (_m *Service) CompareImages(repoName string, chartName string, fromVersion string, toVersion string, values string, options model.RenderOptions) (model.ImageComparison, error) {
+	ret := _m.Called(repoName, chartName, fromVersion, toVersion, values, options)
+
+	var r0 model.ImageComparison
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, model.RenderOptions) (model.ImageComparison, error)); ok {
+		return rf(repoName, chartName, fromVersion, toVersion, values, options)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, model.RenderOptions) model.ImageComparison); ok {
+		r0 = rf(repoName, chartName, fromVersion, toVersion, values, options)
+	} else {
+		r0 = ret.Get(0).(model.ImageComparison)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(repoName, chartName, fromVersion, toVersion, values, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSnapshot provides a mock function with given fields: repoName, chartName, chartVersion, req
+func (_m *Service) CreateSnapshot(repoName string, chartName string, chartVersion string, req model.SnapshotRequest) (model.Snapshot, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, req)
+
+	var r0 model.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, model.SnapshotRequest) (model.Snapshot, error)); ok {
+		return rf(repoName, chartName, chartVersion, req)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, model.SnapshotRequest) model.Snapshot); ok {
+		r0 = rf(repoName, chartName, chartVersion, req)
+	} else {
+		r0 = ret.Get(0).(model.Snapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, model.SnapshotRequest) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePolicy provides a mock function with given fields: id
+func (_m *Service) DeletePolicy(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSnapshot provides a mock function with given fields: id
+func (_m *Service) DeleteSnapshot(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffManifests provides a mock function with given fields: repoName, chartName, from, to
+func (_m *Service) DiffManifests(repoName string, chartName string, from model.RenderSpec, to model.RenderSpec) (model.ManifestDiff, error) {
+	ret := _m.Called(repoName, chartName, from, to)
+
+	var r0 model.ManifestDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, model.RenderSpec, model.RenderSpec) (model.ManifestDiff, error)); ok {
+		return rf(repoName, chartName, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, model.RenderSpec, model.RenderSpec) model.ManifestDiff); ok {
+		r0 = rf(repoName, chartName, from, to)
+	} else {
+		r0 = ret.Get(0).(model.ManifestDiff)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, model.RenderSpec, model.RenderSpec) error); ok {
+		r1 = rf(repoName, chartName, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffTemplates provides a mock function with given fields: repoName, chartName, fromVersion, toVersion
+func (_m *Service) DiffTemplates(repoName string, chartName string, fromVersion string, toVersion string) (model.TemplatesDiff, error) {
+	ret := _m.Called(repoName, chartName, fromVersion, toVersion)
+
+	var r0 model.TemplatesDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (model.TemplatesDiff, error)); ok {
+		return rf(repoName, chartName, fromVersion, toVersion)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) model.TemplatesDiff); ok {
+		r0 = rf(repoName, chartName, fromVersion, toVersion)
+	} else {
+		r0 = ret.Get(0).(model.TemplatesDiff)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(repoName, chartName, fromVersion, toVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffValues provides a mock function with given fields: repoName, chartName, fromVersion, toVersion
+func (_m *Service) DiffValues(repoName string, chartName string, fromVersion string, toVersion string) (model.ValuesDiff, error) {
+	ret := _m.Called(repoName, chartName, fromVersion, toVersion)
+
+	var r0 model.ValuesDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (model.ValuesDiff, error)); ok {
+		return rf(repoName, chartName, fromVersion, toVersion)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) model.ValuesDiff); ok {
+		r0 = rf(repoName, chartName, fromVersion, toVersion)
+	} else {
+		r0 = ret.Get(0).(model.ValuesDiff)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(repoName, chartName, fromVersion, toVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChangelog provides a mock function with given fields: repoName, chartName, fromVersion, toVersion
+func (_m *Service) GetChangelog(repoName string, chartName string, fromVersion string, toVersion string) (model.Changelog, error) {
+	ret := _m.Called(repoName, chartName, fromVersion, toVersion)
+
+	var r0 model.Changelog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (model.Changelog, error)); ok {
+		return rf(repoName, chartName, fromVersion, toVersion)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) model.Changelog); ok {
+		r0 = rf(repoName, chartName, fromVersion, toVersion)
+	} else {
+		r0 = ret.Get(0).(model.Changelog)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(repoName, chartName, fromVersion, toVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChart provides a mock function with given fields: repoName, chartName, chartVersion
 func (_m *Service) GetChart(repoName string, chartName string, chartVersion string) (model.ChartDetail, error) {
 	ret := _m.Called(repoName, chartName, chartVersion)
@@ -63,6 +261,30 @@ func (_m *Service) GetChart(repoName string, chartName string, chartVersion stri
 	return r0, r1
 }
 
+// GetChartReport provides a mock function with given fields: repoName, chartName, chartVersion, values, options
+func (_m *Service) GetChartReport(repoName string, chartName string, chartVersion string, values string, options model.RenderOptions) (model.ChartReport, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, values, options)
+
+	var r0 model.ChartReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions) (model.ChartReport, error)); ok {
+		return rf(repoName, chartName, chartVersion, values, options)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions) model.ChartReport); ok {
+		r0 = rf(repoName, chartName, chartVersion, values, options)
+	} else {
+		r0 = ret.Get(0).(model.ChartReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, values, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCharts provides a mock function with given fields: repoName
 func (_m *Service) GetCharts(repoName string) ([]model.Chart, error) {
 	ret := _m.Called(repoName)
@@ -89,6 +311,130 @@ func (_m *Service) GetCharts(repoName string) ([]model.Chart, error) {
 	return r0, r1
 }
 
+// GetCompatibilityMatrix provides a mock function with given fields: repoName, chartName
+func (_m *Service) GetCompatibilityMatrix(repoName string, chartName string) (model.CompatibilityMatrix, error) {
+	ret := _m.Called(repoName, chartName)
+
+	var r0 model.CompatibilityMatrix
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (model.CompatibilityMatrix, error)); ok {
+		return rf(repoName, chartName)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) model.CompatibilityMatrix); ok {
+		r0 = rf(repoName, chartName)
+	} else {
+		r0 = ret.Get(0).(model.CompatibilityMatrix)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(repoName, chartName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImageInventory provides a mock function with given fields: repoName, chartName, chartVersion, values, options
+func (_m *Service) GetImageInventory(repoName string, chartName string, chartVersion string, values string, options model.RenderOptions) (model.ImageInventory, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, values, options)
+
+	var r0 model.ImageInventory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions) (model.ImageInventory, error)); ok {
+		return rf(repoName, chartName, chartVersion, values, options)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions) model.ImageInventory); ok {
+		r0 = rf(repoName, chartName, chartVersion, values, options)
+	} else {
+		r0 = ret.Get(0).(model.ImageInventory)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, values, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPolicies provides a mock function with given fields:
+func (_m *Service) GetPolicies() ([]model.Policy, error) {
+	ret := _m.Called()
+
+	var r0 []model.Policy
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Policy, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Policy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Policy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRenderValues provides a mock function with given fields: repoName, chartName, chartVersion, hash
+func (_m *Service) GetRenderValues(repoName string, chartName string, chartVersion string, hash string) (map[string]interface{}, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, hash)
+
+	var r0 map[string]interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (map[string]interface{}, error)); ok {
+		return rf(repoName, chartName, chartVersion, hash)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) map[string]interface{}); ok {
+		r0 = rf(repoName, chartName, chartVersion, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRenderedManifests provides a mock function with given fields: repoName, chartName, chartVersion, hash
+func (_m *Service) GetRenderedManifests(repoName string, chartName string, chartVersion string, hash string) (model.ManifestResponse, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, hash)
+
+	var r0 model.ManifestResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (model.ManifestResponse, error)); ok {
+		return rf(repoName, chartName, chartVersion, hash)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) model.ManifestResponse); ok {
+		r0 = rf(repoName, chartName, chartVersion, hash)
+	} else {
+		r0 = ret.Get(0).(model.ManifestResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRepos provides a mock function with given fields:
 func (_m *Service) GetRepos() ([]model.Repo, error) {
 	ret := _m.Called()
@@ -115,23 +461,97 @@ func (_m *Service) GetRepos() ([]model.Repo, error) {
 	return r0, r1
 }
 
-// GetStringifiedManifests provides a mock function with given fields: repoName, chartName, chartVersion, hash
-func (_m *Service) GetStringifiedManifests(repoName string, chartName string, chartVersion string, hash string) (string, error) {
-	ret := _m.Called(repoName, chartName, chartVersion, hash)
+// GetSnapshot provides a mock function with given fields: id
+func (_m *Service) GetSnapshot(id string) (model.Snapshot, error) {
+	ret := _m.Called(id)
+
+	var r0 model.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (model.Snapshot, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) model.Snapshot); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(model.Snapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSnapshots provides a mock function with given fields:
+func (_m *Service) GetSnapshots() ([]model.Snapshot, error) {
+	ret := _m.Called()
+
+	var r0 []model.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]model.Snapshot, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []model.Snapshot); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Snapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStringifiedManifests provides a mock function with given fields: repoName, chartName, chartVersion, hash, withNotes
+func (_m *Service) GetStringifiedManifests(repoName string, chartName string, chartVersion string, hash string, withNotes bool) (string, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, hash, withNotes)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string) (string, error)); ok {
-		return rf(repoName, chartName, chartVersion, hash)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, bool) (string, error)); ok {
+		return rf(repoName, chartName, chartVersion, hash, withNotes)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string, string) string); ok {
-		r0 = rf(repoName, chartName, chartVersion, hash)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, bool) string); ok {
+		r0 = rf(repoName, chartName, chartVersion, hash, withNotes)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
-		r1 = rf(repoName, chartName, chartVersion, hash)
+	if rf, ok := ret.Get(1).(func(string, string, string, string, bool) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, hash, withNotes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStringifiedSnapshot provides a mock function with given fields: id, withNotes
+func (_m *Service) GetStringifiedSnapshot(id string, withNotes bool) (string, error) {
+	ret := _m.Called(id, withNotes)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, bool) (string, error)); ok {
+		return rf(id, withNotes)
+	}
+	if rf, ok := ret.Get(0).(func(string, bool) string); ok {
+		r0 = rf(id, withNotes)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, bool) error); ok {
+		r1 = rf(id, withNotes)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -165,6 +585,30 @@ func (_m *Service) GetTemplates(repoName string, chartName string, chartVersion
 	return r0, r1
 }
 
+// GetUpgradeImpact provides a mock function with given fields: repoName, chartName, fromVersion, toVersion, values, options
+func (_m *Service) GetUpgradeImpact(repoName string, chartName string, fromVersion string, toVersion string, values string, options model.RenderOptions) (model.UpgradeImpact, error) {
+	ret := _m.Called(repoName, chartName, fromVersion, toVersion, values, options)
+
+	var r0 model.UpgradeImpact
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, model.RenderOptions) (model.UpgradeImpact, error)); ok {
+		return rf(repoName, chartName, fromVersion, toVersion, values, options)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, model.RenderOptions) model.UpgradeImpact); ok {
+		r0 = rf(repoName, chartName, fromVersion, toVersion, values, options)
+	} else {
+		r0 = ret.Get(0).(model.UpgradeImpact)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(repoName, chartName, fromVersion, toVersion, values, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetValues provides a mock function with given fields: repoName, chartName, chartVersion
 func (_m *Service) GetValues(repoName string, chartName string, chartVersion string) (map[string]interface{}, error) {
 	ret := _m.Called(repoName, chartName, chartVersion)
@@ -191,23 +635,111 @@ func (_m *Service) GetValues(repoName string, chartName string, chartVersion str
 	return r0, r1
 }
 
-// RenderManifest provides a mock function with given fields: repoName, chartName, chartVersion, values
-func (_m *Service) RenderManifest(repoName string, chartName string, chartVersion string, values string) (model.ManifestResponse, error) {
-	ret := _m.Called(repoName, chartName, chartVersion, values)
+// RenderBatch provides a mock function with given fields: releases
+func (_m *Service) RenderBatch(releases []model.BatchRelease) (model.BatchRender, error) {
+	ret := _m.Called(releases)
+
+	var r0 model.BatchRender
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]model.BatchRelease) (model.BatchRender, error)); ok {
+		return rf(releases)
+	}
+	if rf, ok := ret.Get(0).(func([]model.BatchRelease) model.BatchRender); ok {
+		r0 = rf(releases)
+	} else {
+		r0 = ret.Get(0).(model.BatchRender)
+	}
+
+	if rf, ok := ret.Get(1).(func([]model.BatchRelease) error); ok {
+		r1 = rf(releases)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenderManifest provides a mock function with given fields: repoName, chartName, chartVersion, releaseName, values, options
+func (_m *Service) RenderManifest(repoName string, chartName string, chartVersion string, releaseName string, values string, options model.RenderOptions) (model.ManifestResponse, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, releaseName, values, options)
 
 	var r0 model.ManifestResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string, string) (model.ManifestResponse, error)); ok {
-		return rf(repoName, chartName, chartVersion, values)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, model.RenderOptions) (model.ManifestResponse, error)); ok {
+		return rf(repoName, chartName, chartVersion, releaseName, values, options)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string, string) model.ManifestResponse); ok {
-		r0 = rf(repoName, chartName, chartVersion, values)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string, model.RenderOptions) model.ManifestResponse); ok {
+		r0 = rf(repoName, chartName, chartVersion, releaseName, values, options)
 	} else {
 		r0 = ret.Get(0).(model.ManifestResponse)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
-		r1 = rf(repoName, chartName, chartVersion, values)
+	if rf, ok := ret.Get(1).(func(string, string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, releaseName, values, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RerenderSnapshot provides a mock function with given fields: id, req
+func (_m *Service) RerenderSnapshot(id string, req model.SnapshotRerenderRequest) (model.Snapshot, error) {
+	ret := _m.Called(id, req)
+
+	var r0 model.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, model.SnapshotRerenderRequest) (model.Snapshot, error)); ok {
+		return rf(id, req)
+	}
+	if rf, ok := ret.Get(0).(func(string, model.SnapshotRerenderRequest) model.Snapshot); ok {
+		r0 = rf(id, req)
+	} else {
+		r0 = ret.Get(0).(model.Snapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, model.SnapshotRerenderRequest) error); ok {
+		r1 = rf(id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SavePolicy provides a mock function with given fields: policy
+func (_m *Service) SavePolicy(policy model.Policy) error {
+	ret := _m.Called(policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(model.Policy) error); ok {
+		r0 = rf(policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateManifest provides a mock function with given fields: repoName, chartName, chartVersion, values, options, kubeVersion
+func (_m *Service) ValidateManifest(repoName string, chartName string, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestValidationResult, error) {
+	ret := _m.Called(repoName, chartName, chartVersion, values, options, kubeVersion)
+
+	var r0 []model.ManifestValidationResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions, string) ([]model.ManifestValidationResult, error)); ok {
+		return rf(repoName, chartName, chartVersion, values, options, kubeVersion)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, model.RenderOptions, string) []model.ManifestValidationResult); ok {
+		r0 = rf(repoName, chartName, chartVersion, values, options, kubeVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ManifestValidationResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, model.RenderOptions, string) error); ok {
+		r1 = rf(repoName, chartName, chartVersion, values, options, kubeVersion)
 	} else {
 		r1 = ret.Error(1)
 	}
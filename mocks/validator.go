@@ -0,0 +1,54 @@
+// Code generated by mockery v2.32.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "chart-viewer/pkg/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Validator is an autogenerated mock type for the Validator type
+type Validator struct {
+	mock.Mock
+}
+
+// Validate provides a mock function with given fields: manifests, crds, kubeVersion
+func (_m *Validator) Validate(manifests []model.Manifest, crds []model.Manifest, kubeVersion string) ([]model.ManifestValidationResult, error) {
+	ret := _m.Called(manifests, crds, kubeVersion)
+
+	var r0 []model.ManifestValidationResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]model.Manifest, []model.Manifest, string) ([]model.ManifestValidationResult, error)); ok {
+		return rf(manifests, crds, kubeVersion)
+	}
+	if rf, ok := ret.Get(0).(func([]model.Manifest, []model.Manifest, string) []model.ManifestValidationResult); ok {
+		r0 = rf(manifests, crds, kubeVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ManifestValidationResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]model.Manifest, []model.Manifest, string) error); ok {
+		r1 = rf(manifests, crds, kubeVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewValidator creates a new instance of Validator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewValidator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Validator {
+	mock := &Validator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,22 +2,50 @@ package analyzer
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 
 	"chart-viewer/pkg/model"
 	"gopkg.in/yaml.v3"
 )
 
-type analytic struct{}
+const templatePlaceholder = "__TEMPLATE__"
+
+var (
+	documentSeparator = regexp.MustCompile(`(?m)^---.*$`)
+	templateAction    = regexp.MustCompile(`(?s){{.*?}}`)
+	controlAction     = regexp.MustCompile(`^{{-?\s*(if|else|end|range|with|define|block|/\*)\b`)
+	topLevelField     = regexp.MustCompile(`(?m)^(apiVersion|kind):[ \t]*(.*?)[ \t]*$`)
+	apiVersionField   = regexp.MustCompile(`^apiVersion:[ \t]*(.*?)[ \t]*$`)
+)
+
+// SplitDocuments splits the content of a template or manifest on the YAML
+// document separators.
+func SplitDocuments(content string) []string {
+	return documentSeparator.Split(content, -1)
+}
+
+type analytic struct {
+	rules []Rule
+}
 
 func New() analytic {
-	return analytic{}
+	return NewWithRules(DefaultRules())
 }
 
-func (a analytic) Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error) {
+func NewWithRules(rules []Rule) analytic {
+	return analytic{rules: rules}
+}
+
+func (a analytic) Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion, deprecations []model.APIDeprecation) ([]model.AnalyticsResult, error) {
 	var results []model.AnalyticsResult
 
 	for _, t := range templates {
+		if !isManifestTemplate(t.Name) {
+			continue
+		}
+
 		r := model.AnalyticsResult{
 			Template: model.Template{
 				Name:    t.Name,
@@ -26,22 +54,148 @@ func (a analytic) Analyze(templates []model.Template, kubeAPIVersions model.Kube
 			Compatible: true,
 		}
 
-		apiVersion, err := extractAPIVersion(t.Content)
+		offset := 0
+		for i, document := range documentSeparator.Split(t.Content, -1) {
+			resources, warnings := extractResources(document)
+			for _, w := range warnings {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("document %d: %s", i+1, w))
+			}
+
+			documentOffset := offset
+			offset += strings.Count(document, "\n")
+			if len(resources) == 0 {
+				// The document declares a resource whose apiVersion cannot be
+				// read from the template source.
+				r.Unchecked = r.Unchecked || len(warnings) != 0
+				continue
+			}
+
+			compatible := false
+			for j := range resources {
+				if line := declarationLine(document, resources[j].APIVersion); line != 0 {
+					resources[j].Line = documentOffset + line
+				}
+				resources[j].Compatible = isCompatible(kubeAPIVersions.APIVersions, resources[j].APIVersion)
+				applyDeprecation(&resources[j], kubeAPIVersions.KubeVersion, deprecations)
+				compatible = compatible || resources[j].Compatible
+			}
+
+			r.Compatible = r.Compatible && compatible
+			r.Resources = append(r.Resources, resources...)
+		}
+
+		if len(r.Resources) == 0 && len(r.Warnings) == 0 {
+			continue
+		}
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+func (a analytic) AnalyzeManifests(manifests []model.Manifest, kubeAPIVersions model.KubernetesAPIVersion, deprecations []model.APIDeprecation) ([]model.ManifestAnalyticsResult, error) {
+	var results []model.ManifestAnalyticsResult
+
+	for _, m := range manifests {
+		r := model.ManifestAnalyticsResult{
+			Manifest:   m,
+			Compatible: true,
+		}
+
+		var resource model.KubeResourceCommonSpec
+		err := yaml.Unmarshal([]byte(m.Content), &resource)
 		if err != nil {
-			return nil, err
+			r.Warnings = append(r.Warnings, fmt.Sprintf("cannot parse manifest: %s", err))
+			results = append(results, r)
+			continue
 		}
 
-		if apiVersion == "" {
+		if resource.APIVersion == "" {
 			continue
 		}
 
-		r.Compatible = isCompatible(kubeAPIVersions.APIVersions, apiVersion)
+		analytics := model.ResourceAnalytics{
+			APIVersion: resource.APIVersion,
+			Kind:       resource.Kind,
+			Name:       resource.Metadata.Name,
+			Compatible: isCompatible(kubeAPIVersions.APIVersions, resource.APIVersion),
+		}
+		applyDeprecation(&analytics, kubeAPIVersions.KubeVersion, deprecations)
+
+		r.Compatible = analytics.Compatible
+		r.Resources = []model.ResourceAnalytics{analytics}
 		results = append(results, r)
 	}
 
 	return results, nil
 }
 
+// Check runs the rules on every workload of the rendered manifests and the
+// policies on every object of the kinds they select.
+func (a analytic) Check(manifests []model.Manifest, policies []model.Policy) ([]model.RuleFinding, error) {
+	var compiled []compiledPolicy
+	for _, policy := range policies {
+		c, err := compilePolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, c)
+	}
+
+	var findings []model.RuleFinding
+
+	for _, m := range manifests {
+		for _, document := range documentSeparator.Split(m.Content, -1) {
+			var object map[string]interface{}
+			err := yaml.Unmarshal([]byte(document), &object)
+			if err != nil || len(object) == 0 {
+				continue
+			}
+
+			kind, _ := object["kind"].(string)
+			name, _ := lookup(object, "metadata", "name").(string)
+			finding := func(id, severity, container, message string) model.RuleFinding {
+				return model.RuleFinding{
+					RuleID:    id,
+					Severity:  severity,
+					Manifest:  m.Name,
+					Template:  m.Template,
+					Kind:      kind,
+					Resource:  name,
+					Container: container,
+					Message:   message,
+				}
+			}
+
+			if workload, ok := toWorkload(object); ok {
+				for _, rule := range a.rules {
+					for _, v := range rule.Check(workload) {
+						findings = append(findings, finding(rule.ID, rule.Severity, v.Container, v.Message))
+					}
+				}
+			}
+
+			for _, policy := range compiled {
+				if !policy.appliesTo(kind) {
+					continue
+				}
+
+				if message := policy.evaluate(object); message != "" {
+					findings = append(findings, finding(policy.ID, policy.Severity, "", message))
+				}
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+func isManifestTemplate(name string) bool {
+	base := path.Base(name)
+	return !strings.HasPrefix(base, "_") && base != "NOTES.txt"
+}
+
 func isCompatible(versions []string, version string) bool {
 	exists := false
 	for _, v := range versions {
@@ -58,19 +212,110 @@ func isCompatible(versions []string, version string) bool {
 	return true
 }
 
-func extractAPIVersion(template string) (string, error) {
+// extractResources reads the apiVersion and kind of a single template
+// document. Template actions are replaced before parsing, and when the result
+// is still not valid YAML the top level fields are read line by line. More than
+// one resource is returned when conditionals declare alternative apiVersions.
+func extractResources(document string) ([]model.ResourceAnalytics, []string) {
+	sanitized := sanitizeTemplate(document)
+	if strings.TrimSpace(sanitized) == "" {
+		return nil, nil
+	}
+
+	var warnings []string
 	var resource model.KubeResourceCommonSpec
-	lines := strings.Split(template, "\n")
-
-	for _, line := range lines {
-		if strings.Contains(line, "apiVersion") {
-			err := yaml.Unmarshal([]byte(line), &resource)
-			if err != nil {
-				fmt.Printf("Error unmarshalling template: %s \n\n\n", err)
-				return "", err
+	var resources []model.ResourceAnalytics
+
+	err := yaml.Unmarshal([]byte(sanitized), &resource)
+	if err == nil {
+		if resource.APIVersion != "" {
+			resources = append(resources, model.ResourceAnalytics{
+				APIVersion: resource.APIVersion,
+				Kind:       resource.Kind,
+			})
+		}
+	} else {
+		resources = scanTopLevelFields(sanitized)
+		if len(resources) == 0 {
+			return nil, []string{fmt.Sprintf("cannot parse template: %s", err)}
+		}
+	}
+
+	if len(resources) > 1 {
+		warnings = append(warnings, "alternative apiVersions are declared behind template conditionals")
+	}
+
+	var checkable []model.ResourceAnalytics
+	for _, r := range resources {
+		if strings.Contains(r.APIVersion, templatePlaceholder) {
+			warnings = append(warnings, "apiVersion is set by a template action and can only be checked on rendered manifests")
+			continue
+		}
+
+		r.Kind = strings.ReplaceAll(r.Kind, templatePlaceholder, "")
+		checkable = append(checkable, r)
+	}
+
+	return checkable, warnings
+}
+
+// sanitizeTemplate removes control actions, replaces actions that output a
+// value with a placeholder scalar and drops the lines left without content.
+func sanitizeTemplate(document string) string {
+	replaced := templateAction.ReplaceAllStringFunc(document, func(action string) string {
+		newlines := strings.Repeat("\n", strings.Count(action, "\n"))
+		if controlAction.MatchString(action) {
+			return newlines
+		}
+		return templatePlaceholder + newlines
+	})
+
+	var lines []string
+	for _, line := range strings.Split(replaced, "\n") {
+		if strings.TrimSpace(strings.ReplaceAll(line, templatePlaceholder, "")) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// declarationLine returns the line of the document, starting at 1, where the
+// given apiVersion is declared, or 0 when it is not found.
+func declarationLine(document, apiVersion string) int {
+	for i, line := range strings.Split(document, "\n") {
+		match := apiVersionField.FindStringSubmatch(line)
+		if len(match) != 0 && strings.Trim(match[1], `"'`) == apiVersion {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+func scanTopLevelFields(document string) []model.ResourceAnalytics {
+	var apiVersions []string
+	var kind string
+
+	for _, match := range topLevelField.FindAllStringSubmatch(document, -1) {
+		value := strings.Trim(match[2], `"'`)
+		if match[1] == "kind" {
+			if kind == "" {
+				kind = value
 			}
+			continue
 		}
+		apiVersions = append(apiVersions, value)
+	}
+
+	var resources []model.ResourceAnalytics
+	for _, apiVersion := range apiVersions {
+		resources = append(resources, model.ResourceAnalytics{
+			APIVersion: apiVersion,
+			Kind:       kind,
+		})
 	}
 
-	return resource.APIVersion, nil
+	return resources
 }
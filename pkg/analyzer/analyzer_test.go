@@ -0,0 +1,327 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_analytic_Analyze(t *testing.T) {
+	kubeAPIVersion := model.KubernetesAPIVersion{
+		KubeVersion: "1.22",
+		APIVersions: []string{"v1", "apps/v1", "networking.k8s.io/v1"},
+	}
+
+	deprecations := []model.APIDeprecation{
+		{
+			GroupVersionKind: model.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"},
+			DeprecatedIn:     "1.14",
+			RemovedIn:        "1.22",
+			Replacement:      &model.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		templates []model.Template
+		want      []model.AnalyticsResult
+	}{
+		{
+			name: "should report every resource of a template with several documents",
+			templates: []model.Template{
+				{
+					Name: "templates/app.yaml",
+					Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "app.fullname" . }}
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: {{ include "app.fullname" . }}
+`,
+				},
+			},
+			want: []model.AnalyticsResult{
+				{
+					Compatible: false,
+					Resources: []model.ResourceAnalytics{
+						{APIVersion: "apps/v1", Kind: "Deployment", Line: 1, Compatible: true},
+						{
+							APIVersion:  "extensions/v1beta1",
+							Kind:        "Ingress",
+							Line:        6,
+							Compatible:  false,
+							Deprecated:  true,
+							Removed:     true,
+							Replacement: "networking.k8s.io/v1",
+							Message:     "extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should not fail when a template action line mentions apiVersion",
+			templates: []model.Template{
+				{
+					Name: "templates/service.yaml",
+					Content: `{{- if .Capabilities.APIVersions.Has "v1" }}
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    {{- toYaml .Values.service.annotations | nindent 4 }}
+  labels:
+    apiVersion: {{ .Values.apiVersion }}
+spec:
+  ports:
+    - port: {{ .Values.service.port }}
+{{- end }}
+`,
+				},
+			},
+			want: []model.AnalyticsResult{
+				{
+					Compatible: true,
+					Resources: []model.ResourceAnalytics{
+						{APIVersion: "v1", Kind: "Service", Line: 2, Compatible: true},
+					},
+				},
+			},
+		},
+		{
+			name: "should accept alternative apiVersions behind conditionals",
+			templates: []model.Template{
+				{
+					Name: "templates/ingress.yaml",
+					Content: `{{- if semverCompare ">=1.19-0" .Capabilities.KubeVersion.GitVersion }}
+apiVersion: networking.k8s.io/v1
+{{- else }}
+apiVersion: networking.k8s.io/v1beta1
+{{- end }}
+kind: Ingress
+`,
+				},
+			},
+			want: []model.AnalyticsResult{
+				{
+					Compatible: true,
+					Resources: []model.ResourceAnalytics{
+						{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Line: 2, Compatible: true},
+						{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Line: 4, Compatible: false},
+					},
+					Warnings: []string{"document 1: alternative apiVersions are declared behind template conditionals"},
+				},
+			},
+		},
+		{
+			name: "should warn when apiVersion is computed by a template action",
+			templates: []model.Template{
+				{
+					Name: "templates/ingress.yaml",
+					Content: `apiVersion: {{ include "app.ingress.apiVersion" . }}
+kind: Ingress
+`,
+				},
+			},
+			want: []model.AnalyticsResult{
+				{
+					Compatible: true,
+					Unchecked:  true,
+					Warnings:   []string{"document 1: apiVersion is set by a template action and can only be checked on rendered manifests"},
+				},
+			},
+		},
+		{
+			name: "should warn instead of failing when a template cannot be parsed",
+			templates: []model.Template{
+				{
+					Name:    "templates/broken.yaml",
+					Content: "metadata: [\n",
+				},
+				{
+					Name:    "templates/configmap.yaml",
+					Content: "apiVersion: v1\nkind: ConfigMap\n",
+				},
+			},
+			want: []model.AnalyticsResult{
+				{
+					Compatible: true,
+					Unchecked:  true,
+					Warnings:   []string{"document 1: cannot parse template: yaml: line 1: did not find expected node content"},
+				},
+				{
+					Compatible: true,
+					Resources: []model.ResourceAnalytics{
+						{APIVersion: "v1", Kind: "ConfigMap", Line: 1, Compatible: true},
+					},
+				},
+			},
+		},
+		{
+			name: "should skip helpers and notes",
+			templates: []model.Template{
+				{Name: "templates/_helpers.tpl", Content: `{{- define "app.name" -}}apiVersion{{- end }}`},
+				{Name: "templates/NOTES.txt", Content: "apiVersion: v1 is used"},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.want {
+				tt.want[i].Template = tt.templates[i]
+			}
+
+			actual, err := analyzer.New().Analyze(tt.templates, kubeAPIVersion, deprecations)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_analytic_AnalyzeManifests(t *testing.T) {
+	kubeAPIVersion := model.KubernetesAPIVersion{
+		KubeVersion: "1.22",
+		APIVersions: []string{"v1", "apps/v1", "networking.k8s.io/v1"},
+	}
+
+	manifests := []model.Manifest{
+		{
+			Name:     "ingress.yaml",
+			Template: "templates/ingress.yaml",
+			Content:  "# Source: app/templates/ingress.yaml\napiVersion: networking.k8s.io/v1beta1\nkind: Ingress\nmetadata:\n  name: app\n",
+		},
+		{
+			Name:     "service.yaml",
+			Template: "templates/service.yaml",
+			Content:  "# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\n",
+		},
+		{
+			Name:     "empty.yaml",
+			Template: "templates/empty.yaml",
+			Content:  "# Source: app/templates/empty.yaml\n",
+		},
+	}
+
+	want := []model.ManifestAnalyticsResult{
+		{
+			Manifest:   manifests[0],
+			Compatible: false,
+			Resources: []model.ResourceAnalytics{
+				{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Name: "app", Compatible: false},
+			},
+		},
+		{
+			Manifest:   manifests[1],
+			Compatible: true,
+			Resources: []model.ResourceAnalytics{
+				{APIVersion: "v1", Kind: "Service", Name: "app", Compatible: true},
+			},
+		},
+	}
+
+	actual, err := analyzer.New().AnalyzeManifests(manifests, kubeAPIVersion, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, want, actual)
+}
+
+func Test_analytic_AnalyzeManifests_deprecations(t *testing.T) {
+	deprecations := []model.APIDeprecation{
+		{
+			GroupVersionKind: model.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
+			DeprecatedIn:     "1.21",
+			RemovedIn:        "1.25",
+			Replacement:      &model.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"},
+		},
+		{
+			GroupVersionKind: model.GroupVersionKind{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy"},
+			DeprecatedIn:     "1.21",
+			RemovedIn:        "1.25",
+		},
+	}
+
+	manifests := []model.Manifest{
+		{Name: "cronjob.yaml", Content: "apiVersion: batch/v1beta1\nkind: CronJob\nmetadata:\n  name: app\n"},
+		{Name: "psp.yaml", Content: "apiVersion: policy/v1beta1\nkind: PodSecurityPolicy\nmetadata:\n  name: app\n"},
+	}
+
+	tests := []struct {
+		name           string
+		kubeAPIVersion model.KubernetesAPIVersion
+		want           []model.ResourceAnalytics
+	}{
+		{
+			name:           "should not report APIs that are not deprecated yet",
+			kubeAPIVersion: model.KubernetesAPIVersion{KubeVersion: "1.20", APIVersions: []string{"batch/v1beta1", "policy/v1beta1"}},
+			want: []model.ResourceAnalytics{
+				{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "app", Compatible: true},
+				{APIVersion: "policy/v1beta1", Kind: "PodSecurityPolicy", Name: "app", Compatible: true},
+			},
+		},
+		{
+			name:           "should report deprecated APIs that are still served",
+			kubeAPIVersion: model.KubernetesAPIVersion{KubeVersion: "1.23", APIVersions: []string{"batch/v1", "batch/v1beta1", "policy/v1beta1"}},
+			want: []model.ResourceAnalytics{
+				{
+					APIVersion:  "batch/v1beta1",
+					Kind:        "CronJob",
+					Name:        "app",
+					Compatible:  true,
+					Deprecated:  true,
+					Replacement: "batch/v1",
+					Message:     "batch/v1beta1 CronJob deprecated in 1.21 and removed in 1.25, use batch/v1",
+				},
+				{
+					APIVersion: "policy/v1beta1",
+					Kind:       "PodSecurityPolicy",
+					Name:       "app",
+					Compatible: true,
+					Deprecated: true,
+					Message:    "policy/v1beta1 PodSecurityPolicy deprecated in 1.21 and removed in 1.25",
+				},
+			},
+		},
+		{
+			name:           "should report removed APIs as incompatible",
+			kubeAPIVersion: model.KubernetesAPIVersion{KubeVersion: "1.25", APIVersions: []string{"batch/v1", "policy/v1beta1"}},
+			want: []model.ResourceAnalytics{
+				{
+					APIVersion:  "batch/v1beta1",
+					Kind:        "CronJob",
+					Name:        "app",
+					Compatible:  false,
+					Deprecated:  true,
+					Removed:     true,
+					Replacement: "batch/v1",
+					Message:     "batch/v1beta1 CronJob removed in 1.25, use batch/v1",
+				},
+				{
+					APIVersion: "policy/v1beta1",
+					Kind:       "PodSecurityPolicy",
+					Name:       "app",
+					Compatible: false,
+					Deprecated: true,
+					Removed:    true,
+					Message:    "policy/v1beta1 PodSecurityPolicy removed in 1.25",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := analyzer.New().AnalyzeManifests(manifests, tt.kubeAPIVersion, deprecations)
+			assert.NoError(t, err)
+
+			var actual []model.ResourceAnalytics
+			for _, r := range results {
+				actual = append(actual, r.Resources...)
+			}
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
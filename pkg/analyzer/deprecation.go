@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"chart-viewer/pkg/model"
+	"github.com/Masterminds/semver/v3"
+)
+
+// applyDeprecation marks a resource as deprecated or removed in the given
+// Kubernetes version and explains which API replaces it. A removed API is
+// never compatible, even when the API version list still contains it.
+func applyDeprecation(resource *model.ResourceAnalytics, kubeVersion string, deprecations []model.APIDeprecation) {
+	deprecation, ok := findDeprecation(deprecations, resource.APIVersion, resource.Kind)
+	if !ok || kubeVersion == "" {
+		return
+	}
+
+	resource.Removed = reachedVersion(kubeVersion, deprecation.RemovedIn)
+	resource.Deprecated = resource.Removed || reachedVersion(kubeVersion, deprecation.DeprecatedIn)
+	if !resource.Deprecated {
+		return
+	}
+
+	if resource.Removed {
+		resource.Compatible = false
+		resource.Message = fmt.Sprintf("%s %s removed in %s", resource.APIVersion, resource.Kind, deprecation.RemovedIn)
+	} else {
+		resource.Message = fmt.Sprintf("%s %s deprecated in %s", resource.APIVersion, resource.Kind, deprecation.DeprecatedIn)
+		if deprecation.RemovedIn != "" {
+			resource.Message += fmt.Sprintf(" and removed in %s", deprecation.RemovedIn)
+		}
+	}
+
+	if deprecation.Replacement != nil {
+		resource.Replacement = deprecation.Replacement.APIVersion()
+		resource.Message += ", use " + resource.Replacement
+		if deprecation.Replacement.Kind != "" && deprecation.Replacement.Kind != resource.Kind {
+			resource.Message += " " + deprecation.Replacement.Kind
+		}
+	}
+}
+
+func findDeprecation(deprecations []model.APIDeprecation, apiVersion, kind string) (model.APIDeprecation, bool) {
+	for _, d := range deprecations {
+		if d.APIVersion() == apiVersion && d.Kind == kind {
+			return d, true
+		}
+	}
+
+	return model.APIDeprecation{}, false
+}
+
+func reachedVersion(kubeVersion, target string) bool {
+	if target == "" {
+		return false
+	}
+
+	current, err := semver.NewVersion(kubeVersion)
+	if err != nil {
+		return false
+	}
+
+	constraint, err := semver.NewVersion(target)
+	if err != nil {
+		return false
+	}
+
+	return !current.LessThan(constraint)
+}
@@ -0,0 +1,202 @@
+package analyzer
+
+import (
+	"sort"
+
+	"chart-viewer/pkg/model"
+	"github.com/docker/distribution/reference"
+	"gopkg.in/yaml.v3"
+)
+
+// containerFields are the pod spec fields that list containers. They are
+// searched at any depth, so pod templates of custom resources are found too.
+var containerFields = []string{"initContainers", "containers", "ephemeralContainers"}
+
+// Images lists every container image of the rendered manifests, once per
+// normalized reference, with the containers that use it.
+func (a analytic) Images(manifests []model.Manifest) ([]model.ContainerImage, error) {
+	index := map[string]int{}
+	var images []model.ContainerImage
+
+	for _, m := range manifests {
+		for _, document := range documentSeparator.Split(m.Content, -1) {
+			var object map[string]interface{}
+			err := yaml.Unmarshal([]byte(document), &object)
+			if err != nil || len(object) == 0 {
+				continue
+			}
+
+			kind, _ := object["kind"].(string)
+			name, _ := lookup(object, "metadata", "name").(string)
+
+			walkContainers(object, func(field string, container map[string]interface{}) {
+				image, _ := container["image"].(string)
+				if image == "" {
+					return
+				}
+
+				containerName, _ := container["name"].(string)
+				ref := model.ImageReference{
+					Manifest:  m.Name,
+					Template:  m.Template,
+					Kind:      kind,
+					Resource:  name,
+					Container: containerName,
+					Init:      field == "initContainers",
+				}
+
+				parsed := ParseImage(image)
+				i, ok := index[parsed.Image]
+				if !ok {
+					i = len(images)
+					index[parsed.Image] = i
+					images = append(images, parsed)
+				}
+				images[i].References = append(images[i].References, ref)
+			})
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Image < images[j].Image
+	})
+
+	return images, nil
+}
+
+// ParseImage resolves the registry, repository, tag and digest of an image
+// reference the way the container runtime does, so nginx becomes
+// docker.io/library/nginx:latest. References that cannot be parsed, like ones
+// left with template placeholders, are returned as written with the error.
+func ParseImage(image string) model.ContainerImage {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return model.ContainerImage{Image: image, Error: err.Error()}
+	}
+
+	named = reference.TagNameOnly(named)
+	parsed := model.ContainerImage{
+		Image:      named.String(),
+		Registry:   reference.Domain(named),
+		Repository: reference.Path(named),
+	}
+
+	if tagged, ok := named.(reference.Tagged); ok {
+		parsed.Tag = tagged.Tag()
+	}
+
+	if digested, ok := named.(reference.Digested); ok {
+		parsed.Digest = digested.Digest().String()
+	}
+
+	return parsed
+}
+
+// CompareImages tells which images are only used by one of the versions. An
+// image whose repository is used by both versions with another tag or digest
+// is reported as changed instead.
+func (a analytic) CompareImages(from, to []model.ContainerImage) model.ImageComparison {
+	comparison := model.ImageComparison{
+		Added:     []model.ContainerImage{},
+		Removed:   []model.ContainerImage{},
+		Changed:   []model.ImageChange{},
+		Unchanged: []model.ContainerImage{},
+	}
+
+	toImages := map[string]bool{}
+	for _, image := range to {
+		toImages[image.Image] = true
+	}
+
+	fromImages := map[string]bool{}
+	removed := map[string][]model.ContainerImage{}
+	var removedOrder []model.ContainerImage
+	for _, image := range from {
+		fromImages[image.Image] = true
+		if toImages[image.Image] {
+			continue
+		}
+
+		key := imageRepository(image)
+		removed[key] = append(removed[key], image)
+		removedOrder = append(removedOrder, image)
+	}
+
+	paired := map[string]bool{}
+	for _, image := range to {
+		if fromImages[image.Image] {
+			comparison.Unchanged = append(comparison.Unchanged, image)
+			continue
+		}
+
+		key := imageRepository(image)
+		if len(removed[key]) == 0 {
+			comparison.Added = append(comparison.Added, image)
+			continue
+		}
+
+		previous := removed[key][0]
+		removed[key] = removed[key][1:]
+		paired[previous.Image] = true
+		comparison.Changed = append(comparison.Changed, model.ImageChange{
+			Registry:   image.Registry,
+			Repository: image.Repository,
+			From:       previous,
+			To:         image,
+		})
+	}
+
+	for _, image := range removedOrder {
+		if !paired[image.Image] {
+			comparison.Removed = append(comparison.Removed, image)
+		}
+	}
+
+	return comparison
+}
+
+func imageRepository(image model.ContainerImage) string {
+	if image.Repository == "" {
+		return image.Image
+	}
+
+	return image.Registry + "/" + image.Repository
+}
+
+func walkContainers(value interface{}, fn func(field string, container map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range containerFields {
+			for _, container := range objects(v[field]) {
+				fn(field, container)
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if isContainerField(key) {
+				continue
+			}
+			walkContainers(v[key], fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkContainers(item, fn)
+		}
+	}
+}
+
+func isContainerField(key string) bool {
+	for _, field := range containerFields {
+		if key == field {
+			return true
+		}
+	}
+
+	return false
+}
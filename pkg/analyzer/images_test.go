@@ -0,0 +1,157 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_analytic_Images(t *testing.T) {
+	manifests := []model.Manifest{
+		{
+			Name:     "deployment.yaml",
+			Template: "templates/deployment.yaml",
+			Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: registry.example.com:5000/team/app:1.2.0
+      containers:
+        - name: app
+          image: registry.example.com:5000/team/app:1.2.0
+        - name: proxy
+          image: nginx
+`,
+		},
+		{
+			Name:     "cronjob.yaml",
+			Template: "templates/cronjob.yaml",
+			Content: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+              image: ghcr.io/acme/backup@sha256:0123456789012345678901234567890123456789012345678901234567890123
+`,
+		},
+		{
+			Name:     "rollout.yaml",
+			Template: "templates/rollout.yaml",
+			Content: `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:latest
+`,
+		},
+	}
+
+	want := []model.ContainerImage{
+		{
+			Image:      "docker.io/library/nginx:latest",
+			Registry:   "docker.io",
+			Repository: "library/nginx",
+			Tag:        "latest",
+			References: []model.ImageReference{
+				{Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "proxy"},
+				{Manifest: "rollout.yaml", Template: "templates/rollout.yaml", Kind: "Rollout", Resource: "app", Container: "app"},
+			},
+		},
+		{
+			Image:      "ghcr.io/acme/backup@sha256:0123456789012345678901234567890123456789012345678901234567890123",
+			Registry:   "ghcr.io",
+			Repository: "acme/backup",
+			Digest:     "sha256:0123456789012345678901234567890123456789012345678901234567890123",
+			References: []model.ImageReference{
+				{Manifest: "cronjob.yaml", Template: "templates/cronjob.yaml", Kind: "CronJob", Resource: "backup", Container: "backup"},
+			},
+		},
+		{
+			Image:      "registry.example.com:5000/team/app:1.2.0",
+			Registry:   "registry.example.com:5000",
+			Repository: "team/app",
+			Tag:        "1.2.0",
+			References: []model.ImageReference{
+				{Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "migrate", Init: true},
+				{Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app"},
+			},
+		},
+	}
+
+	actual, err := analyzer.New().Images(manifests)
+	assert.NoError(t, err)
+	assert.Equal(t, want, actual)
+}
+
+func Test_ParseImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  model.ContainerImage
+	}{
+		{
+			name:  "should resolve the docker hub library image",
+			image: "redis:7",
+			want:  model.ContainerImage{Image: "docker.io/library/redis:7", Registry: "docker.io", Repository: "library/redis", Tag: "7"},
+		},
+		{
+			name:  "should keep the tag and the digest",
+			image: "quay.io/prometheus/node-exporter:v1.6.0@sha256:0123456789012345678901234567890123456789012345678901234567890123",
+			want: model.ContainerImage{
+				Image:      "quay.io/prometheus/node-exporter:v1.6.0@sha256:0123456789012345678901234567890123456789012345678901234567890123",
+				Registry:   "quay.io",
+				Repository: "prometheus/node-exporter",
+				Tag:        "v1.6.0",
+				Digest:     "sha256:0123456789012345678901234567890123456789012345678901234567890123",
+			},
+		},
+		{
+			name:  "should return the error of an invalid reference",
+			image: "Registry/App:1.0",
+			want:  model.ContainerImage{Image: "Registry/App:1.0", Error: "invalid reference format: repository name must be lowercase"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, analyzer.ParseImage(tt.image))
+		})
+	}
+}
+
+func Test_analytic_CompareImages(t *testing.T) {
+	nginx124 := analyzer.ParseImage("nginx:1.24")
+	nginx125 := analyzer.ParseImage("nginx:1.25")
+	redis := analyzer.ParseImage("redis:7")
+	busybox := analyzer.ParseImage("busybox:1.36")
+	exporter := analyzer.ParseImage("nginx/nginx-prometheus-exporter:0.11")
+
+	actual := analyzer.New().CompareImages(
+		[]model.ContainerImage{nginx124, exporter, redis},
+		[]model.ContainerImage{busybox, nginx125, exporter},
+	)
+
+	assert.Equal(t, model.ImageComparison{
+		Added:     []model.ContainerImage{busybox},
+		Removed:   []model.ContainerImage{redis},
+		Changed:   []model.ImageChange{{Registry: "docker.io", Repository: "library/nginx", From: nginx124, To: nginx125}},
+		Unchanged: []model.ContainerImage{exporter},
+	}, actual)
+}
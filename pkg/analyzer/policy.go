@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"chart-viewer/pkg/model"
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
+// compiledPolicy is a policy whose CEL expression is ready to be evaluated
+// against the `object` variable, which holds a rendered Kubernetes object.
+type compiledPolicy struct {
+	model.Policy
+	program cel.Program
+}
+
+// CompilePolicy checks that a policy has an ID, a known severity and a CEL
+// expression returning a bool.
+func (a analytic) CompilePolicy(policy model.Policy) error {
+	_, err := compilePolicy(policy)
+	return err
+}
+
+func compilePolicy(policy model.Policy) (compiledPolicy, error) {
+	if policy.ID == "" {
+		return compiledPolicy{}, fmt.Errorf("%w: id is required", model.ErrInvalidPolicy)
+	}
+
+	switch policy.Severity {
+	case SeverityHigh, SeverityMedium, SeverityLow:
+	default:
+		return compiledPolicy{}, fmt.Errorf("%w: %s: severity must be one of %s, %s or %s", model.ErrInvalidPolicy, policy.ID, SeverityHigh, SeverityMedium, SeverityLow)
+	}
+
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return compiledPolicy{}, err
+	}
+
+	ast, issues := env.Compile(policy.Expression)
+	if issues != nil && issues.Err() != nil {
+		return compiledPolicy{}, fmt.Errorf("%w: %s: %s", model.ErrInvalidPolicy, policy.ID, issues.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return compiledPolicy{}, fmt.Errorf("%w: %s: expression must return a bool, got %s", model.ErrInvalidPolicy, policy.ID, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return compiledPolicy{}, fmt.Errorf("%w: %s: %s", model.ErrInvalidPolicy, policy.ID, err)
+	}
+
+	return compiledPolicy{Policy: policy, program: program}, nil
+}
+
+func (p compiledPolicy) appliesTo(kind string) bool {
+	if len(p.Kinds) == 0 {
+		return true
+	}
+
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluate returns the violation message, or an empty string when the object
+// satisfies the policy.
+func (p compiledPolicy) evaluate(object map[string]interface{}) string {
+	out, _, err := p.program.Eval(map[string]interface{}{"object": object})
+	if err != nil {
+		return fmt.Sprintf("cannot evaluate policy: %s", err)
+	}
+
+	if passed, ok := out.Value().(bool); !ok {
+		return fmt.Sprintf("policy returned %v instead of a bool", out.Value())
+	} else if passed {
+		return ""
+	}
+
+	switch {
+	case p.Message != "":
+		return p.Message
+	case p.Description != "":
+		return p.Description
+	default:
+		return fmt.Sprintf("policy %s is not satisfied", p.ID)
+	}
+}
+
+// LoadPolicies reads every YAML or JSON policy file of a directory. A file
+// holds either one policy or a list of policies.
+func LoadPolicies(dir string) ([]model.Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []model.Policy
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var filePolicies []model.Policy
+		if err := yaml.Unmarshal(content, &filePolicies); err != nil {
+			var policy model.Policy
+			if err := yaml.Unmarshal(content, &policy); err != nil {
+				return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+			}
+			filePolicies = []model.Policy{policy}
+		}
+
+		for _, policy := range filePolicies {
+			if _, err := compilePolicy(policy); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,109 @@
+package analyzer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_analytic_Check_policies(t *testing.T) {
+	policies := []model.Policy{
+		{
+			ID:          "deployment-name-label",
+			Severity:    "medium",
+			Description: "Deployments must set app.kubernetes.io/name",
+			Kinds:       []string{"Deployment"},
+			Expression:  "has(object.metadata.labels) && 'app.kubernetes.io/name' in object.metadata.labels",
+		},
+		{
+			ID:         "no-load-balancer",
+			Severity:   "high",
+			Kinds:      []string{"Service"},
+			Expression: "!has(object.spec.type) || object.spec.type != 'LoadBalancer'",
+			Message:    "LoadBalancer Services are not allowed",
+		},
+	}
+
+	manifests := []model.Manifest{
+		{Name: "service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  type: LoadBalancer\n"},
+		{Name: "internal.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: internal\nspec:\n  ports: []\n"},
+		{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"},
+		{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  template:\n    spec:\n      automountServiceAccountToken: false\n"},
+	}
+
+	actual, err := analyzer.NewWithRules(nil).Check(manifests, policies)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.RuleFinding{
+		{RuleID: "no-load-balancer", Severity: "high", Manifest: "service.yaml", Kind: "Service", Resource: "app", Message: "LoadBalancer Services are not allowed"},
+		{RuleID: "deployment-name-label", Severity: "medium", Manifest: "deployment.yaml", Kind: "Deployment", Resource: "app", Message: "Deployments must set app.kubernetes.io/name"},
+	}, actual)
+}
+
+func Test_analytic_CompilePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  model.Policy
+		wantErr string
+	}{
+		{
+			name:   "should accept a valid policy",
+			policy: model.Policy{ID: "replicas", Severity: "low", Expression: "object.spec.replicas > 1"},
+		},
+		{
+			name:    "should reject a policy without id",
+			policy:  model.Policy{Severity: "low", Expression: "true"},
+			wantErr: "invalid policy: id is required",
+		},
+		{
+			name:    "should reject an unknown severity",
+			policy:  model.Policy{ID: "replicas", Severity: "critical", Expression: "true"},
+			wantErr: "invalid policy: replicas: severity must be one of high, medium or low",
+		},
+		{
+			name:    "should reject an expression that does not return a bool",
+			policy:  model.Policy{ID: "replicas", Severity: "low", Expression: "'replicas'"},
+			wantErr: "invalid policy: replicas: expression must return a bool, got string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := analyzer.New().CompilePolicy(tt.policy)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, model.ErrInvalidPolicy)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_LoadPolicies(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "labels.yaml"), []byte(`id: deployment-name-label
+severity: medium
+kinds: [Deployment]
+expression: "'app.kubernetes.io/name' in object.metadata.labels"
+`), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "services.yaml"), []byte(`- id: no-load-balancer
+  severity: high
+  kinds: [Service]
+  expression: object.spec.type != 'LoadBalancer'
+`), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "README.md"), []byte("# policies"), 0644)
+	assert.NoError(t, err)
+
+	policies, err := analyzer.LoadPolicies(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Policy{
+		{ID: "deployment-name-label", Severity: "medium", Kinds: []string{"Deployment"}, Expression: "'app.kubernetes.io/name' in object.metadata.labels"},
+		{ID: "no-load-balancer", Severity: "high", Kinds: []string{"Service"}, Expression: "object.spec.type != 'LoadBalancer'"},
+	}, policies)
+}
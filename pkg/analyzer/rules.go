@@ -0,0 +1,267 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Rule checks the pod spec of a rendered workload. Check returns one violation
+// per problem found.
+type Rule struct {
+	ID          string
+	Severity    string
+	Description string
+	Check       func(w Workload) []Violation
+}
+
+type Violation struct {
+	Container string
+	Message   string
+}
+
+// Workload is a rendered object that runs pods, with the pod spec found at
+// the path its kind declares it.
+type Workload struct {
+	Kind    string
+	Name    string
+	PodSpec map[string]interface{}
+}
+
+func (w Workload) Containers() []map[string]interface{} {
+	return objects(w.PodSpec["containers"])
+}
+
+func (w Workload) AllContainers() []map[string]interface{} {
+	return append(objects(w.PodSpec["initContainers"]), w.Containers()...)
+}
+
+func (w Workload) isJob() bool {
+	return w.Kind == "Job" || w.Kind == "CronJob"
+}
+
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			ID:          "privileged-container",
+			Severity:    SeverityHigh,
+			Description: "Containers should not run in privileged mode",
+			Check: perContainer(func(w Workload, c map[string]interface{}) string {
+				if lookup(c, "securityContext", "privileged") == true {
+					return "container runs in privileged mode"
+				}
+				return ""
+			}),
+		},
+		{
+			ID:          "host-path-volume",
+			Severity:    SeverityHigh,
+			Description: "Pods should not mount hostPath volumes",
+			Check: func(w Workload) []Violation {
+				var violations []Violation
+				for _, v := range objects(w.PodSpec["volumes"]) {
+					if path := lookup(v, "hostPath", "path"); path != nil {
+						violations = append(violations, Violation{Message: fmt.Sprintf("volume %v mounts host path %v", v["name"], path)})
+					}
+				}
+				return violations
+			},
+		},
+		{
+			ID:          "run-as-root",
+			Severity:    SeverityHigh,
+			Description: "Containers should set runAsNonRoot and not run as user 0",
+			Check: perContainer(func(w Workload, c map[string]interface{}) string {
+				runAsUser := lookup(c, "securityContext", "runAsUser")
+				if runAsUser == nil {
+					runAsUser = lookup(w.PodSpec, "securityContext", "runAsUser")
+				}
+				if isZero(runAsUser) {
+					return "container runs as user 0"
+				}
+
+				runAsNonRoot := lookup(c, "securityContext", "runAsNonRoot")
+				if runAsNonRoot == nil {
+					runAsNonRoot = lookup(w.PodSpec, "securityContext", "runAsNonRoot")
+				}
+				if runAsNonRoot != true {
+					return "container may run as root, runAsNonRoot is not set"
+				}
+				return ""
+			}),
+		},
+		{
+			ID:          "missing-resource-requests",
+			Severity:    SeverityMedium,
+			Description: "Containers should request CPU and memory",
+			Check: perContainer(func(w Workload, c map[string]interface{}) string {
+				return missingResources(c, "requests")
+			}),
+		},
+		{
+			ID:          "missing-resource-limits",
+			Severity:    SeverityMedium,
+			Description: "Containers should limit CPU and memory",
+			Check: perContainer(func(w Workload, c map[string]interface{}) string {
+				return missingResources(c, "limits")
+			}),
+		},
+		{
+			ID:          "latest-image-tag",
+			Severity:    SeverityMedium,
+			Description: "Images should be pinned to a tag other than latest or to a digest",
+			Check: perContainer(func(w Workload, c map[string]interface{}) string {
+				image, _ := c["image"].(string)
+				if image == "" || strings.Contains(image, "@") {
+					return ""
+				}
+
+				name := image[strings.LastIndex(image, "/")+1:]
+				if !strings.Contains(name, ":") || strings.HasSuffix(name, ":latest") {
+					return fmt.Sprintf("image %s uses the latest tag", image)
+				}
+				return ""
+			}),
+		},
+		{
+			ID:          "missing-liveness-probe",
+			Severity:    SeverityLow,
+			Description: "Long running containers should declare a liveness probe",
+			Check:       missingProbe("livenessProbe"),
+		},
+		{
+			ID:          "missing-readiness-probe",
+			Severity:    SeverityLow,
+			Description: "Long running containers should declare a readiness probe",
+			Check:       missingProbe("readinessProbe"),
+		},
+		{
+			ID:          "automount-service-account-token",
+			Severity:    SeverityLow,
+			Description: "Pods should set automountServiceAccountToken to false unless they call the Kubernetes API",
+			Check: func(w Workload) []Violation {
+				if w.PodSpec["automountServiceAccountToken"] == false {
+					return nil
+				}
+				return []Violation{{Message: "service account token is mounted automatically"}}
+			},
+		},
+	}
+}
+
+// workloadPodSpecPaths lists where the pod spec of each workload kind is.
+var workloadPodSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+func toWorkload(object map[string]interface{}) (Workload, bool) {
+	kind, _ := object["kind"].(string)
+	path, ok := workloadPodSpecPaths[kind]
+	if !ok {
+		return Workload{}, false
+	}
+
+	podSpec, ok := lookup(object, path...).(map[string]interface{})
+	if !ok {
+		return Workload{}, false
+	}
+
+	name, _ := lookup(object, "metadata", "name").(string)
+	return Workload{Kind: kind, Name: name, PodSpec: podSpec}, true
+}
+
+func perContainer(check func(w Workload, c map[string]interface{}) string) func(w Workload) []Violation {
+	return func(w Workload) []Violation {
+		var violations []Violation
+		for _, c := range w.AllContainers() {
+			if message := check(w, c); message != "" {
+				name, _ := c["name"].(string)
+				violations = append(violations, Violation{Container: name, Message: message})
+			}
+		}
+		return violations
+	}
+}
+
+func missingProbe(probe string) func(w Workload) []Violation {
+	return func(w Workload) []Violation {
+		if w.isJob() {
+			return nil
+		}
+
+		var violations []Violation
+		for _, c := range w.Containers() {
+			if c[probe] == nil {
+				name, _ := c["name"].(string)
+				violations = append(violations, Violation{Container: name, Message: fmt.Sprintf("container has no %s", probe)})
+			}
+		}
+		return violations
+	}
+}
+
+func missingResources(c map[string]interface{}, field string) string {
+	var missing []string
+	for _, resource := range []string{"cpu", "memory"} {
+		if lookup(c, "resources", field, resource) == nil {
+			missing = append(missing, resource)
+		}
+	}
+
+	if len(missing) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("container has no %s %s", strings.Join(missing, " and "), field)
+}
+
+func lookup(object map[string]interface{}, path ...string) interface{} {
+	var current interface{} = object
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+
+	return current
+}
+
+func objects(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+
+	var result []map[string]interface{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case int:
+		return v == 0
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	default:
+		return false
+	}
+}
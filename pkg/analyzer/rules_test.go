@@ -0,0 +1,125 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_analytic_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifests []model.Manifest
+		want      []model.RuleFinding
+	}{
+		{
+			name: "should report every rule broken by a workload",
+			manifests: []model.Manifest{
+				{
+					Name:     "deployment.yaml",
+					Template: "templates/deployment.yaml",
+					Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      volumes:
+        - name: docker
+          hostPath:
+            path: /var/run/docker.sock
+      containers:
+        - name: app
+          image: nginx
+          securityContext:
+            privileged: true
+            runAsUser: 0
+`,
+				},
+			},
+			want: []model.RuleFinding{
+				{RuleID: "privileged-container", Severity: "high", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container runs in privileged mode"},
+				{RuleID: "host-path-volume", Severity: "high", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Message: "volume docker mounts host path /var/run/docker.sock"},
+				{RuleID: "run-as-root", Severity: "high", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container runs as user 0"},
+				{RuleID: "missing-resource-requests", Severity: "medium", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container has no cpu and memory requests"},
+				{RuleID: "missing-resource-limits", Severity: "medium", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container has no cpu and memory limits"},
+				{RuleID: "latest-image-tag", Severity: "medium", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "image nginx uses the latest tag"},
+				{RuleID: "missing-liveness-probe", Severity: "low", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container has no livenessProbe"},
+				{RuleID: "missing-readiness-probe", Severity: "low", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container has no readinessProbe"},
+				{RuleID: "automount-service-account-token", Severity: "low", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Message: "service account token is mounted automatically"},
+			},
+		},
+		{
+			name: "should not report a hardened cron job",
+			manifests: []model.Manifest{
+				{
+					Name: "cronjob.yaml",
+					Content: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          automountServiceAccountToken: false
+          securityContext:
+            runAsNonRoot: true
+          containers:
+            - name: backup
+              image: registry.example.com:5000/backup@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945
+              resources:
+                requests:
+                  cpu: 100m
+                  memory: 64Mi
+                limits:
+                  cpu: 200m
+                  memory: 128Mi
+`,
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "should ignore objects that do not run pods",
+			manifests: []model.Manifest{
+				{Name: "service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n"},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := analyzer.New().Check(tt.manifests, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_analytic_Check_customRules(t *testing.T) {
+	rule := analyzer.Rule{
+		ID:       "host-network",
+		Severity: analyzer.SeverityHigh,
+		Check: func(w analyzer.Workload) []analyzer.Violation {
+			if w.PodSpec["hostNetwork"] == true {
+				return []analyzer.Violation{{Message: "pod uses the host network"}}
+			}
+			return nil
+		},
+	}
+
+	manifests := []model.Manifest{
+		{Name: "pod.yaml", Content: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\nspec:\n  hostNetwork: true\n"},
+	}
+
+	actual, err := analyzer.NewWithRules([]analyzer.Rule{rule}).Check(manifests, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.RuleFinding{
+		{RuleID: "host-network", Severity: "high", Manifest: "pod.yaml", Kind: "Pod", Resource: "app", Message: "pod uses the host network"},
+	}, actual)
+}
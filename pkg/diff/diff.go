@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"chart-viewer/pkg/model"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const diffContext = 3
+
+type changes struct {
+	added   []model.ValueChange
+	removed []model.ValueChange
+	changed []model.ValueChange
+}
+
+// Values compares two values maps key by key. Lists and scalars are compared
+// as a whole, and a key that is only in one of the maps is reported once with
+// its whole subtree. Paths are JSON pointers.
+func Values(from, to map[string]interface{}) model.ValuesDiff {
+	c := changes{
+		added:   []model.ValueChange{},
+		removed: []model.ValueChange{},
+		changed: []model.ValueChange{},
+	}
+
+	c.compare("", from, to, false)
+	return model.ValuesDiff{
+		Added:   c.added,
+		Removed: c.removed,
+		Changed: c.changed,
+	}
+}
+
+// compare walks both values and records the differences. When lists is set,
+// lists are compared item by item instead of as a whole.
+func (c *changes) compare(path string, from, to interface{}, lists bool) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		c.compareMaps(path, fromMap, toMap, lists)
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if lists && fromIsList && toIsList {
+		c.compareLists(path, fromList, toList)
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		c.changed = append(c.changed, model.ValueChange{Path: path, Old: from, New: to})
+	}
+}
+
+func (c *changes) compareMaps(path string, from, to map[string]interface{}, lists bool) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path + "/" + escapePointer(key)
+		oldValue, inFrom := from[key]
+		newValue, inTo := to[key]
+
+		switch {
+		case !inTo:
+			c.removed = append(c.removed, model.ValueChange{Path: keyPath, Old: oldValue})
+		case !inFrom:
+			c.added = append(c.added, model.ValueChange{Path: keyPath, New: newValue})
+		default:
+			c.compare(keyPath, oldValue, newValue, lists)
+		}
+	}
+}
+
+func (c *changes) compareLists(path string, from, to []interface{}) {
+	for i := 0; i < len(from) || i < len(to); i++ {
+		itemPath := path + "/" + strconv.Itoa(i)
+		switch {
+		case i >= len(to):
+			c.removed = append(c.removed, model.ValueChange{Path: itemPath, Old: from[i]})
+		case i >= len(from):
+			c.added = append(c.added, model.ValueChange{Path: itemPath, New: to[i]})
+		default:
+			c.compare(itemPath, from[i], to[i], true)
+		}
+	}
+}
+
+// escapePointer escapes a key as a JSON pointer reference token, RFC 6901.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func unifiedText(fromFile, toFile, from, to string) string {
+	text, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(from),
+		B:        splitLines(to),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  diffContext,
+	})
+
+	return text
+}
+
+// splitLines splits the text after each newline, keeping a last line that
+// does not end with one.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,80 @@
+package diff_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Values(t *testing.T) {
+	from := map[string]interface{}{
+		"replicaCount": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.24",
+		},
+		"ingress": map[string]interface{}{
+			"enabled": false,
+			"hosts":   []interface{}{"chart.local"},
+		},
+		"podAnnotations": map[string]interface{}{
+			"prometheus.io/scrape": "true",
+		},
+		"serviceAccount": map[string]interface{}{
+			"create": true,
+		},
+		"resources": map[string]interface{}{},
+	}
+	to := map[string]interface{}{
+		"replicaCount": 1,
+		"image": map[string]interface{}{
+			"registry":   "docker.io",
+			"repository": "nginx",
+			"tag":        "1.25",
+		},
+		"ingress": map[string]interface{}{
+			"enabled": false,
+			"hosts":   []interface{}{"chart.local", "chart.example.com"},
+		},
+		"podAnnotations": map[string]interface{}{
+			"prometheus.io/scrape": "false",
+		},
+		"serviceAccount": true,
+		"resources":      map[string]interface{}{},
+	}
+
+	want := model.ValuesDiff{
+		Added: []model.ValueChange{
+			{Path: "/image/registry", New: "docker.io"},
+		},
+		Removed: []model.ValueChange{},
+		Changed: []model.ValueChange{
+			{Path: "/image/tag", Old: "1.24", New: "1.25"},
+			{Path: "/ingress/hosts", Old: []interface{}{"chart.local"}, New: []interface{}{"chart.local", "chart.example.com"}},
+			{Path: "/podAnnotations/prometheus.io~1scrape", Old: "true", New: "false"},
+			{Path: "/serviceAccount", Old: map[string]interface{}{"create": true}, New: true},
+		},
+	}
+
+	assert.Equal(t, want, diff.Values(from, to))
+}
+
+func Test_Values_removed(t *testing.T) {
+	from := map[string]interface{}{
+		"legacy": map[string]interface{}{"enabled": true},
+		"a~b":    nil,
+	}
+
+	want := model.ValuesDiff{
+		Added: []model.ValueChange{},
+		Removed: []model.ValueChange{
+			{Path: "/a~0b"},
+			{Path: "/legacy", Old: map[string]interface{}{"enabled": true}},
+		},
+		Changed: []model.ValueChange{},
+	}
+
+	assert.Equal(t, want, diff.Values(from, map[string]interface{}{}))
+}
@@ -0,0 +1,64 @@
+package diff
+
+import (
+	"sort"
+
+	"chart-viewer/pkg/model"
+)
+
+// Duplicates returns the objects rendered more than once in the manifests of
+// several releases, with the release and manifest of every occurrence. Objects
+// are matched by API group, kind, namespace and name, as the API server stores
+// them, so objects without a namespace match whatever namespace their releases
+// are installed in. A release with a manifest that cannot be parsed is left out
+// of the check and its error is returned by release name.
+func Duplicates(manifests []model.ReleaseManifest) ([]model.DuplicateObject, map[string]error) {
+	decoded := make([][]object, len(manifests))
+	errs := map[string]error{}
+	for i, m := range manifests {
+		if _, failed := errs[m.Release]; failed {
+			continue
+		}
+
+		objects, err := decodeObjects(m.Manifest)
+		if err != nil {
+			errs[m.Release] = err
+			continue
+		}
+		decoded[i] = objects
+	}
+
+	var keys []model.ObjectIdentity
+	found := map[model.ObjectIdentity]*model.DuplicateObject{}
+	for i, m := range manifests {
+		if _, failed := errs[m.Release]; failed {
+			continue
+		}
+
+		for _, o := range decoded[i] {
+			key := groupIdentity(o.identity)
+			if found[key] == nil {
+				found[key] = &model.DuplicateObject{ObjectIdentity: o.identity}
+				keys = append(keys, key)
+			}
+			found[key].Occurrences = append(found[key].Occurrences, model.ObjectOccurrence{
+				Release:    m.Release,
+				Manifest:   m.Name,
+				APIVersion: o.identity.APIVersion,
+			})
+		}
+	}
+
+	duplicates := []model.DuplicateObject{}
+	for _, key := range keys {
+		if len(found[key].Occurrences) > 1 {
+			duplicates = append(duplicates, *found[key])
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		return lessIdentity(duplicates[i].ObjectIdentity, duplicates[j].ObjectIdentity)
+	})
+
+	return duplicates, errs
+}
@@ -0,0 +1,81 @@
+package diff_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Duplicates(t *testing.T) {
+	manifests := []model.ReleaseManifest{
+		{
+			Release:  "web",
+			Manifest: model.Manifest{Name: "serviceaccount.yaml", Content: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: app\n"},
+		},
+		{
+			Release:  "web",
+			Manifest: model.Manifest{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: web\n"},
+		},
+		{
+			Release:  "worker",
+			Manifest: model.Manifest{Name: "rbac.yaml", Content: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: app\n---\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: app\n"},
+		},
+		{
+			Release:  "worker",
+			Manifest: model.Manifest{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: worker\n"},
+		},
+		{
+			Release:  "cron",
+			Manifest: model.Manifest{Name: "clusterrole.yaml", Content: "# Source: cron/templates/clusterrole.yaml\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: app\n"},
+		},
+		{
+			Release:  "cron",
+			Manifest: model.Manifest{Name: "empty.yaml", Content: "# Source: cron/templates/empty.yaml\n"},
+		},
+		{
+			Release:  "web",
+			Manifest: model.Manifest{Name: "pdb.yaml", Content: "apiVersion: policy/v1beta1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\n  namespace: apps\n"},
+		},
+		{
+			Release:  "worker",
+			Manifest: model.Manifest{Name: "pdb.yaml", Content: "apiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\n  namespace: apps\n"},
+		},
+		{
+			Release:  "broken",
+			Manifest: model.Manifest{Name: "serviceaccount.yaml", Content: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: app\n"},
+		},
+		{
+			Release:  "broken",
+			Manifest: model.Manifest{Name: "broken.yaml", Content: "kind: [Service"},
+		},
+	}
+
+	got, errs := diff.Duplicates(manifests)
+	assert.Equal(t, []model.DuplicateObject{
+		{
+			ObjectIdentity: model.ObjectIdentity{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "app"},
+			Occurrences: []model.ObjectOccurrence{
+				{Release: "worker", Manifest: "rbac.yaml", APIVersion: "rbac.authorization.k8s.io/v1"},
+				{Release: "cron", Manifest: "clusterrole.yaml", APIVersion: "rbac.authorization.k8s.io/v1"},
+			},
+		},
+		{
+			ObjectIdentity: model.ObjectIdentity{APIVersion: "policy/v1beta1", Kind: "PodDisruptionBudget", Namespace: "apps", Name: "app"},
+			Occurrences: []model.ObjectOccurrence{
+				{Release: "web", Manifest: "pdb.yaml", APIVersion: "policy/v1beta1"},
+				{Release: "worker", Manifest: "pdb.yaml", APIVersion: "policy/v1"},
+			},
+		},
+		{
+			ObjectIdentity: model.ObjectIdentity{APIVersion: "v1", Kind: "ServiceAccount", Name: "app"},
+			Occurrences: []model.ObjectOccurrence{
+				{Release: "web", Manifest: "serviceaccount.yaml", APIVersion: "v1"},
+				{Release: "worker", Manifest: "rbac.yaml", APIVersion: "v1"},
+			},
+		},
+	}, got)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs["broken"], "cannot parse manifest broken.yaml: yaml: line 1: did not find expected ',' or ']'")
+}
@@ -0,0 +1,242 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+type object struct {
+	identity model.ObjectIdentity
+	manifest string
+	content  map[string]interface{}
+}
+
+// Manifests matches the objects of two renders by apiVersion, kind, namespace
+// and name, and compares the fields of the objects found in both. Objects are
+// compared as parsed YAML, so document order, formatting and comments do not
+// count as changes.
+func Manifests(from, to []model.Manifest) (model.ManifestDiff, error) {
+	return compareManifests(from, to, func(identity model.ObjectIdentity) model.ObjectIdentity {
+		return identity
+	})
+}
+
+// UpgradeManifests matches the objects by API group instead of apiVersion, as
+// the API server does, so an object whose apiVersion changed is compared to
+// its previous version and the apiVersion is listed with its changes.
+func UpgradeManifests(from, to []model.Manifest) (model.ManifestDiff, error) {
+	return compareManifests(from, to, groupIdentity)
+}
+
+// groupIdentity keeps only the group of the apiVersion of the identity.
+func groupIdentity(identity model.ObjectIdentity) model.ObjectIdentity {
+	identity.APIVersion = kubeversion.NewGroupVersionKind(identity.APIVersion, identity.Kind).Group
+	return identity
+}
+
+func compareManifests(from, to []model.Manifest, key func(model.ObjectIdentity) model.ObjectIdentity) (model.ManifestDiff, error) {
+	fromObjects, fromWarnings, err := parseObjects(from, key)
+	if err != nil {
+		return model.ManifestDiff{}, err
+	}
+
+	toObjects, toWarnings, err := parseObjects(to, key)
+	if err != nil {
+		return model.ManifestDiff{}, err
+	}
+
+	result := model.ManifestDiff{
+		Added:    []model.ObjectDiff{},
+		Removed:  []model.ObjectDiff{},
+		Changed:  []model.ObjectDiff{},
+		Warnings: append(prefixed("from", fromWarnings), prefixed("to", toWarnings)...),
+	}
+
+	for _, k := range sortedIdentities(fromObjects, toObjects) {
+		oldObject, inFrom := fromObjects[k]
+		newObject, inTo := toObjects[k]
+
+		switch {
+		case !inTo:
+			result.Removed = append(result.Removed, model.ObjectDiff{
+				ObjectIdentity: oldObject.identity,
+				Manifest:       oldObject.manifest,
+				Diff:           unified(oldObject.identity, oldObject.content, nil),
+			})
+		case !inFrom:
+			result.Added = append(result.Added, model.ObjectDiff{
+				ObjectIdentity: newObject.identity,
+				Manifest:       newObject.manifest,
+				Diff:           unified(newObject.identity, nil, newObject.content),
+			})
+		case reflect.DeepEqual(oldObject.content, newObject.content):
+			result.Unchanged++
+		default:
+			c := changes{}
+			c.compare("", oldObject.content, newObject.content, true)
+			result.Changed = append(result.Changed, model.ObjectDiff{
+				ObjectIdentity: newObject.identity,
+				Manifest:       newObject.manifest,
+				Added:          c.added,
+				Removed:        c.removed,
+				Changed:        c.changed,
+				Diff:           unified(newObject.identity, oldObject.content, newObject.content),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// Unified joins the text diff of every object, ordered by object identity.
+func Unified(d model.ManifestDiff) string {
+	var objects []model.ObjectDiff
+	objects = append(objects, d.Removed...)
+	objects = append(objects, d.Changed...)
+	objects = append(objects, d.Added...)
+	sort.SliceStable(objects, func(i, j int) bool {
+		return lessIdentity(objects[i].ObjectIdentity, objects[j].ObjectIdentity)
+	})
+
+	var text strings.Builder
+	for _, o := range objects {
+		text.WriteString(o.Diff)
+	}
+
+	return text.String()
+}
+
+// parseObjects indexes the objects of the manifests by the key of their
+// identity. An object whose key was already rendered is left out of the index
+// and reported in the warnings, so the diff compares the first one.
+func parseObjects(manifests []model.Manifest, key func(model.ObjectIdentity) model.ObjectIdentity) (map[model.ObjectIdentity]object, []string, error) {
+	objects := map[model.ObjectIdentity]object{}
+	var warnings []string
+	for _, m := range manifests {
+		decoded, err := decodeObjects(m)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, o := range decoded {
+			k := key(o.identity)
+			if first, ok := objects[k]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s of %s is also rendered by %s, only the first one is compared", o.identity.String(), m.Name, first.manifest))
+				continue
+			}
+
+			objects[k] = o
+		}
+	}
+
+	return objects, warnings, nil
+}
+
+func prefixed(render string, warnings []string) []string {
+	var result []string
+	for _, w := range warnings {
+		result = append(result, render+": "+w)
+	}
+
+	return result
+}
+
+// decodeObjects parses the documents of a manifest, skipping the ones that are
+// not Kubernetes objects.
+func decodeObjects(m model.Manifest) ([]object, error) {
+	var objects []object
+	decoder := yaml.NewDecoder(strings.NewReader(m.Content))
+	for {
+		var content map[string]interface{}
+		err := decoder.Decode(&content)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse manifest %s: %w", m.Name, err)
+		}
+
+		kind, _ := content["kind"].(string)
+		if kind == "" {
+			continue
+		}
+
+		identity := model.ObjectIdentity{Kind: kind}
+		identity.APIVersion, _ = content["apiVersion"].(string)
+		if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+			identity.Namespace, _ = metadata["namespace"].(string)
+			identity.Name, _ = metadata["name"].(string)
+		}
+
+		objects = append(objects, object{identity: identity, manifest: m.Name, content: content})
+	}
+
+	return objects, nil
+}
+
+func sortedIdentities(from, to map[model.ObjectIdentity]object) []model.ObjectIdentity {
+	var identities []model.ObjectIdentity
+	for identity := range from {
+		identities = append(identities, identity)
+	}
+	for identity := range to {
+		if _, ok := from[identity]; !ok {
+			identities = append(identities, identity)
+		}
+	}
+
+	sort.Slice(identities, func(i, j int) bool {
+		return lessIdentity(identities[i], identities[j])
+	})
+
+	return identities
+}
+
+func lessIdentity(a, b model.ObjectIdentity) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+
+	return a.APIVersion < b.APIVersion
+}
+
+// unified renders both objects as YAML with sorted keys and returns their
+// unified diff. A nil object is an object missing from that render.
+func unified(identity model.ObjectIdentity, from, to map[string]interface{}) string {
+	fromFile, toFile := "a/"+identity.String(), "b/"+identity.String()
+	if from == nil {
+		fromFile = "/dev/null"
+	}
+	if to == nil {
+		toFile = "/dev/null"
+	}
+
+	return unifiedText(fromFile, toFile, marshal(from), marshal(to))
+}
+
+func marshal(content map[string]interface{}) string {
+	if content == nil {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	encoder.Encode(content)
+	return buffer.String()
+}
@@ -0,0 +1,172 @@
+package diff_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Manifests(t *testing.T) {
+	from := []model.Manifest{
+		{
+			Name: "deployment.yaml",
+			Content: `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.24
+`,
+		},
+		{
+			Name:    "service.yaml",
+			Content: "# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  ports:\n  - port: 80\n",
+		},
+		{
+			Name:    "configmap.yaml",
+			Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: apps\n",
+		},
+	}
+	to := []model.Manifest{
+		{
+			Name:    "service.yaml",
+			Content: "# Source: app/templates/svc.yaml\napiVersion: v1\nkind: Service\nmetadata: {name: app}\nspec:\n  ports: [{port: 80}]\n",
+		},
+		{
+			Name: "deployment.yaml",
+			Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.25
+        - name: exporter
+          image: nginx/nginx-prometheus-exporter:0.11
+`,
+		},
+		{
+			Name:    "serviceaccount.yaml",
+			Content: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: app\n",
+		},
+	}
+
+	want := model.ManifestDiff{
+		Added: []model.ObjectDiff{
+			{
+				ObjectIdentity: model.ObjectIdentity{APIVersion: "v1", Kind: "ServiceAccount", Name: "app"},
+				Manifest:       "serviceaccount.yaml",
+				Diff: `--- /dev/null
++++ b/ServiceAccount/app
+@@ -0,0 +1,4 @@
++apiVersion: v1
++kind: ServiceAccount
++metadata:
++  name: app
+`,
+			},
+		},
+		Removed: []model.ObjectDiff{
+			{
+				ObjectIdentity: model.ObjectIdentity{APIVersion: "v1", Kind: "ConfigMap", Namespace: "apps", Name: "app"},
+				Manifest:       "configmap.yaml",
+				Diff: `--- a/ConfigMap/apps/app
++++ /dev/null
+@@ -1,5 +0,0 @@
+-apiVersion: v1
+-kind: ConfigMap
+-metadata:
+-  name: app
+-  namespace: apps
+`,
+			},
+		},
+		Changed: []model.ObjectDiff{
+			{
+				ObjectIdentity: model.ObjectIdentity{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				Manifest:       "deployment.yaml",
+				Added: []model.ValueChange{
+					{Path: "/spec/template/spec/containers/1", New: map[string]interface{}{"name": "exporter", "image": "nginx/nginx-prometheus-exporter:0.11"}},
+				},
+				Removed: []model.ValueChange{
+					{Path: "/spec/replicas", Old: 1},
+				},
+				Changed: []model.ValueChange{
+					{Path: "/spec/template/spec/containers/0/image", Old: "nginx:1.24", New: "nginx:1.25"},
+				},
+				Diff: `--- a/Deployment/app
++++ b/Deployment/app
+@@ -3,9 +3,10 @@
+ metadata:
+   name: app
+ spec:
+-  replicas: 1
+   template:
+     spec:
+       containers:
+-        - image: nginx:1.24
++        - image: nginx:1.25
+           name: app
++        - image: nginx/nginx-prometheus-exporter:0.11
++          name: exporter
+`,
+			},
+		},
+		Unchanged: 1,
+	}
+
+	actual, err := diff.Manifests(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, want, actual)
+}
+
+func Test_Manifests_invalid(t *testing.T) {
+	_, err := diff.Manifests([]model.Manifest{{Name: "broken.yaml", Content: "kind: [\n"}}, nil)
+	assert.EqualError(t, err, "cannot parse manifest broken.yaml: yaml: line 1: did not find expected node content")
+}
+
+func Test_Manifests_duplicates(t *testing.T) {
+	from := []model.Manifest{
+		{Name: "service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  type: ClusterIP\n"},
+		{Name: "service-lb.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  type: LoadBalancer\n"},
+	}
+	to := []model.Manifest{
+		{Name: "service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  type: ClusterIP\n"},
+	}
+
+	actual, err := diff.Manifests(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, actual.Unchanged)
+	assert.Empty(t, actual.Changed)
+	assert.Equal(t, []string{"from: Service/app of service-lb.yaml is also rendered by service.yaml, only the first one is compared"}, actual.Warnings)
+}
+
+func Test_UpgradeManifests(t *testing.T) {
+	from := []model.Manifest{{Name: "pdb.yaml", Content: "apiVersion: policy/v1beta1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\nspec:\n  maxUnavailable: 1\n"}}
+	to := []model.Manifest{{Name: "pdb.yaml", Content: "apiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\nspec:\n  maxUnavailable: 1\n"}}
+
+	actual, err := diff.UpgradeManifests(from, to)
+	assert.NoError(t, err)
+	assert.Empty(t, actual.Added)
+	assert.Empty(t, actual.Removed)
+	assert.Len(t, actual.Changed, 1)
+	assert.Equal(t, model.ObjectIdentity{APIVersion: "policy/v1", Kind: "PodDisruptionBudget", Name: "app"}, actual.Changed[0].ObjectIdentity)
+	assert.Equal(t, []model.ValueChange{{Path: "/apiVersion", Old: "policy/v1beta1", New: "policy/v1"}}, actual.Changed[0].Changed)
+
+	// The manifests diff keeps telling the API versions apart.
+	actual, err = diff.Manifests(from, to)
+	assert.NoError(t, err)
+	assert.Len(t, actual.Added, 1)
+	assert.Len(t, actual.Removed, 1)
+}
@@ -0,0 +1,203 @@
+package diff
+
+import (
+	"math"
+	"sort"
+	"text/template/parse"
+
+	"chart-viewer/pkg/model"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// renameThreshold is the minimum similarity, in percent, for a removed and an
+// added template to be reported as a rename, the same default as git.
+const renameThreshold = 50
+
+// Templates compares the template files of two chart versions. A removed and
+// an added file with similar content are reported as a rename. For files
+// that define named templates, like _helpers.tpl, the added, removed and
+// modified definitions are listed.
+func Templates(from, to []model.Template) model.TemplatesDiff {
+	result := model.TemplatesDiff{
+		Added:    []model.TemplateChange{},
+		Removed:  []model.TemplateChange{},
+		Modified: []model.TemplateChange{},
+		Renamed:  []model.TemplateChange{},
+	}
+
+	fromTemplates := map[string]string{}
+	for _, t := range from {
+		fromTemplates[t.Name] = t.Content
+	}
+
+	toTemplates := map[string]string{}
+	for _, t := range to {
+		toTemplates[t.Name] = t.Content
+	}
+
+	var removed, added []model.Template
+	for _, t := range sortedTemplates(from) {
+		if _, ok := toTemplates[t.Name]; !ok {
+			removed = append(removed, t)
+		}
+	}
+
+	for _, t := range sortedTemplates(to) {
+		content, ok := fromTemplates[t.Name]
+		switch {
+		case !ok:
+			added = append(added, t)
+		case content == t.Content:
+			result.Unchanged++
+		default:
+			result.Modified = append(result.Modified, templateChange(model.Template{Name: t.Name, Content: content}, t, 0))
+		}
+	}
+
+	renamedFrom := map[string]bool{}
+	renamedTo := map[string]bool{}
+	for _, pair := range similarPairs(removed, added) {
+		if renamedFrom[pair.from.Name] || renamedTo[pair.to.Name] {
+			continue
+		}
+
+		renamedFrom[pair.from.Name] = true
+		renamedTo[pair.to.Name] = true
+		result.Renamed = append(result.Renamed, templateChange(pair.from, pair.to, pair.similarity))
+	}
+
+	sort.SliceStable(result.Renamed, func(i, j int) bool {
+		return result.Renamed[i].Name < result.Renamed[j].Name
+	})
+
+	for _, t := range removed {
+		if !renamedFrom[t.Name] {
+			result.Removed = append(result.Removed, model.TemplateChange{
+				Name:               t.Name,
+				RemovedDefinitions: sortedKeys(definitions(t)),
+				Diff:               unifiedText("a/"+t.Name, "/dev/null", t.Content, ""),
+			})
+		}
+	}
+
+	for _, t := range added {
+		if !renamedTo[t.Name] {
+			result.Added = append(result.Added, model.TemplateChange{
+				Name:             t.Name,
+				AddedDefinitions: sortedKeys(definitions(t)),
+				Diff:             unifiedText("/dev/null", "b/"+t.Name, "", t.Content),
+			})
+		}
+	}
+
+	return result
+}
+
+type templatePair struct {
+	from       model.Template
+	to         model.Template
+	similarity int
+}
+
+// similarPairs returns the removed and added templates similar enough to be a
+// rename, most similar first.
+func similarPairs(removed, added []model.Template) []templatePair {
+	var pairs []templatePair
+	for _, r := range removed {
+		for _, a := range added {
+			similarity := similarity(r.Content, a.Content)
+			if similarity >= renameThreshold {
+				pairs = append(pairs, templatePair{from: r, to: a, similarity: similarity})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].similarity > pairs[j].similarity
+	})
+
+	return pairs
+}
+
+func similarity(a, b string) int {
+	if a == b {
+		return 100
+	}
+
+	matcher := difflib.NewMatcher(splitLines(a), splitLines(b))
+	return int(math.Floor(matcher.Ratio() * 100))
+}
+
+func templateChange(from, to model.Template, similarity int) model.TemplateChange {
+	change := model.TemplateChange{
+		Name:       to.Name,
+		Similarity: similarity,
+		Diff:       unifiedText("a/"+from.Name, "b/"+to.Name, from.Content, to.Content),
+	}
+
+	if from.Name != to.Name {
+		change.OldName = from.Name
+	}
+
+	fromDefinitions := definitions(from)
+	toDefinitions := definitions(to)
+	for _, name := range sortedKeys(toDefinitions) {
+		body, ok := fromDefinitions[name]
+		if !ok {
+			change.AddedDefinitions = append(change.AddedDefinitions, name)
+		} else if body != toDefinitions[name] {
+			change.ModifiedDefinitions = append(change.ModifiedDefinitions, name)
+		}
+	}
+
+	for _, name := range sortedKeys(fromDefinitions) {
+		if _, ok := toDefinitions[name]; !ok {
+			change.RemovedDefinitions = append(change.RemovedDefinitions, name)
+		}
+	}
+
+	return change
+}
+
+// definitions returns the named templates defined in a template file with
+// their parsed body, so formatting inside actions does not count as a change.
+// Files that cannot be parsed have no definitions.
+func definitions(t model.Template) map[string]string {
+	tree := parse.New(t.Name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+
+	_, err := tree.Parse(t.Content, "", "", trees)
+	if err != nil {
+		return nil
+	}
+
+	result := map[string]string{}
+	for name, definition := range trees {
+		if name == t.Name || definition.Root == nil {
+			continue
+		}
+		result[name] = definition.Root.String()
+	}
+
+	return result
+}
+
+func sortedTemplates(templates []model.Template) []model.Template {
+	sorted := append([]model.Template(nil), templates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
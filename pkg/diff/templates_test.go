@@ -0,0 +1,126 @@
+package diff_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "app.selectorLabels" . | nindent 6 }}
+`
+
+func Test_Templates(t *testing.T) {
+	from := []model.Template{
+		{Name: "templates/deployment.yaml", Content: deploymentTemplate},
+		{Name: "templates/service.yaml", Content: "apiVersion: v1\nkind: Service\n"},
+		{Name: "templates/ingress.yaml", Content: "apiVersion: networking.k8s.io/v1beta1\nkind: Ingress\n"},
+		{
+			Name: "templates/_helpers.tpl",
+			Content: `{{- define "app.name" -}}
+{{ .Chart.Name }}
+{{- end }}
+
+{{- define "app.fullname" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end }}
+
+{{- define "app.chart" -}}
+{{ .Chart.Name }}-{{ .Chart.Version }}
+{{- end }}
+`,
+		},
+	}
+	to := []model.Template{
+		{Name: "templates/service.yaml", Content: "apiVersion: v1\nkind: Service\n"},
+		{Name: "templates/workload/deployment.yaml", Content: deploymentTemplate + "  template:\n    spec: {}\n"},
+		{Name: "templates/hpa.yaml", Content: "apiVersion: autoscaling/v2\nkind: HorizontalPodAutoscaler\n"},
+		{
+			Name: "templates/_helpers.tpl",
+			Content: `{{- define "app.name" -}}
+{{   .Chart.Name   }}
+{{- end }}
+
+{{- define "app.fullname" -}}
+{{ .Release.Name | trunc 63 }}
+{{- end }}
+
+{{- define "app.labels" -}}
+app: {{ include "app.name" . }}
+{{- end }}
+`,
+		},
+	}
+
+	want := model.TemplatesDiff{
+		Added: []model.TemplateChange{
+			{
+				Name: "templates/hpa.yaml",
+				Diff: "--- /dev/null\n+++ b/templates/hpa.yaml\n@@ -0,0 +1,2 @@\n+apiVersion: autoscaling/v2\n+kind: HorizontalPodAutoscaler\n",
+			},
+		},
+		Removed: []model.TemplateChange{
+			{
+				Name: "templates/ingress.yaml",
+				Diff: "--- a/templates/ingress.yaml\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-apiVersion: networking.k8s.io/v1beta1\n-kind: Ingress\n",
+			},
+		},
+		Modified: []model.TemplateChange{
+			{
+				Name:                "templates/_helpers.tpl",
+				AddedDefinitions:    []string{"app.labels"},
+				RemovedDefinitions:  []string{"app.chart"},
+				ModifiedDefinitions: []string{"app.fullname"},
+				Diff: `--- a/templates/_helpers.tpl
++++ b/templates/_helpers.tpl
+@@ -1,11 +1,11 @@
+ {{- define "app.name" -}}
+-{{ .Chart.Name }}
++{{   .Chart.Name   }}
+ {{- end }}
+ 
+ {{- define "app.fullname" -}}
+-{{ .Release.Name }}-{{ .Chart.Name }}
++{{ .Release.Name | trunc 63 }}
+ {{- end }}
+ 
+-{{- define "app.chart" -}}
+-{{ .Chart.Name }}-{{ .Chart.Version }}
++{{- define "app.labels" -}}
++app: {{ include "app.name" . }}
+ {{- end }}
+`,
+			},
+		},
+		Renamed: []model.TemplateChange{
+			{
+				Name:       "templates/workload/deployment.yaml",
+				OldName:    "templates/deployment.yaml",
+				Similarity: 91,
+				Diff: `--- a/templates/deployment.yaml
++++ b/templates/workload/deployment.yaml
+@@ -9,3 +9,5 @@
+   selector:
+     matchLabels:
+       {{- include "app.selectorLabels" . | nindent 6 }}
++  template:
++    spec: {}
+`,
+			},
+		},
+		Unchanged: 1,
+	}
+
+	assert.Equal(t, want, diff.Templates(from, to))
+}
@@ -0,0 +1,158 @@
+package diff
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"chart-viewer/pkg/model"
+)
+
+// immutableFields are the fields the API server rejects changes to, by kind.
+// Changing one of them needs the object to be deleted and created again.
+var immutableFields = map[string][]string{
+	"DaemonSet":             {"/spec/selector"},
+	"Deployment":            {"/spec/selector"},
+	"Job":                   {"/spec/selector", "/spec/template", "/spec/completionMode"},
+	"PersistentVolumeClaim": {"/spec/accessModes", "/spec/dataSource", "/spec/selector", "/spec/storageClassName", "/spec/volumeMode", "/spec/volumeName"},
+	"ReplicaSet":            {"/spec/selector"},
+	"Service":               {"/spec/clusterIP", "/spec/clusterIPs"},
+	"StatefulSet":           {"/spec/podManagementPolicy", "/spec/selector", "/spec/serviceName", "/spec/volumeClaimTemplates"},
+}
+
+// Impact combines the diffs of an upgrade into the changes that need
+// attention: the removed default values still set in the given values, with
+// the key they were likely renamed to, the objects whose immutable fields
+// changed, the removed objects and the image changes. The manifests diff is
+// expected from UpgradeManifests, so an object that only moved to another
+// apiVersion is not reported as removed.
+func Impact(values model.ValuesDiff, userValues map[string]interface{}, manifests model.ManifestDiff, images model.ImageComparison) model.UpgradeImpact {
+	result := model.UpgradeImpact{
+		Values:           []model.ValueImpact{},
+		Recreated:        []model.RecreateImpact{},
+		RemovedResources: []model.ObjectIdentity{},
+		ImageChanges:     images.Changed,
+		AddedImages:      images.Added,
+		Warnings:         manifests.Warnings,
+	}
+
+	for _, removed := range values.Removed {
+		value, ok := lookup(userValues, removed.Path)
+		if !ok {
+			continue
+		}
+
+		result.Values = append(result.Values, model.ValueImpact{
+			Path:      removed.Path,
+			Value:     value,
+			RenamedTo: renamedTo(removed, values.Added),
+		})
+	}
+
+	for _, changed := range manifests.Changed {
+		fields := changedFields(immutableFields[changed.Kind], changed)
+		if len(fields) != 0 {
+			result.Recreated = append(result.Recreated, model.RecreateImpact{
+				ObjectIdentity: changed.ObjectIdentity,
+				Manifest:       changed.Manifest,
+				Fields:         fields,
+			})
+		}
+	}
+
+	for _, removed := range manifests.Removed {
+		result.RemovedResources = append(result.RemovedResources, removed.ObjectIdentity)
+	}
+
+	if result.ImageChanges == nil {
+		result.ImageChanges = []model.ImageChange{}
+	}
+	if result.AddedImages == nil {
+		result.AddedImages = []model.ContainerImage{}
+	}
+
+	return result
+}
+
+// renamedTo returns the only added key with the same name and default as the
+// removed key, or an empty string when there is none or more than one. Keys
+// nested in an added key are searched too, since moving a key under a new
+// parent adds the parent as a whole.
+func renamedTo(removed model.ValueChange, added []model.ValueChange) string {
+	name := removed.Path[strings.LastIndex(removed.Path, "/")+1:]
+	var candidates []string
+	for _, a := range added {
+		candidates = append(candidates, findKey(a.Path, a.New, name, removed.Old)...)
+	}
+
+	if len(candidates) != 1 {
+		return ""
+	}
+
+	return candidates[0]
+}
+
+func findKey(path string, value interface{}, name string, want interface{}) []string {
+	var result []string
+	if path[strings.LastIndex(path, "/")+1:] == name && reflect.DeepEqual(value, want) {
+		result = append(result, path)
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		for _, key := range sortedValueKeys(m) {
+			result = append(result, findKey(path+"/"+escapePointer(key), m[key], name, want)...)
+		}
+	}
+
+	return result
+}
+
+func sortedValueKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func changedFields(fields []string, object model.ObjectDiff) []string {
+	var paths []string
+	for _, changes := range [][]model.ValueChange{object.Added, object.Removed, object.Changed} {
+		for _, c := range changes {
+			paths = append(paths, c.Path)
+		}
+	}
+
+	var result []string
+	for _, field := range fields {
+		for _, path := range paths {
+			if path == field || strings.HasPrefix(path, field+"/") {
+				result = append(result, field)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// lookup returns the value at a JSON pointer, RFC 6901.
+func lookup(values map[string]interface{}, pointer string) (interface{}, bool) {
+	var current interface{} = values
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		current, ok = m[token]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
@@ -0,0 +1,74 @@
+package diff_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Impact(t *testing.T) {
+	values := diff.Values(
+		map[string]interface{}{
+			"image":   map[string]interface{}{"pullPolicy": "IfNotPresent"},
+			"legacy":  map[string]interface{}{"enabled": false},
+			"service": map[string]interface{}{"port": 80},
+			"unused":  true,
+		},
+		map[string]interface{}{
+			"controller": map[string]interface{}{"image": map[string]interface{}{"pullPolicy": "IfNotPresent"}},
+			"service":    map[string]interface{}{"port": 80},
+		},
+	)
+	userValues := map[string]interface{}{
+		"image":  map[string]interface{}{"pullPolicy": "Always"},
+		"legacy": map[string]interface{}{"enabled": true},
+	}
+
+	manifests, err := diff.UpgradeManifests(
+		[]model.Manifest{
+			{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 1\n  selector:\n    matchLabels:\n      app: app\n"},
+			{Name: "statefulset.yaml", Content: "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\nspec:\n  replicas: 1\n  volumeClaimTemplates:\n    - spec:\n        resources:\n          requests:\n            storage: 1Gi\n"},
+			{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: legacy\n"},
+			{Name: "pdb.yaml", Content: "apiVersion: policy/v1beta1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\nspec:\n  maxUnavailable: 1\n"},
+		},
+		[]model.Manifest{
+			{Name: "pdb.yaml", Content: "apiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\nspec:\n  maxUnavailable: 1\n"},
+			{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 1\n  selector:\n    matchLabels:\n      app.kubernetes.io/name: app\n"},
+			{Name: "statefulset.yaml", Content: "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\nspec:\n  replicas: 2\n  volumeClaimTemplates:\n    - spec:\n        resources:\n          requests:\n            storage: 2Gi\n"},
+		},
+	)
+	assert.NoError(t, err)
+
+	nginx := model.ImageChange{
+		Registry:   "docker.io",
+		Repository: "library/nginx",
+		From:       model.ContainerImage{Image: "docker.io/library/nginx:1.24", Tag: "1.24"},
+		To:         model.ContainerImage{Image: "docker.io/library/nginx:1.25", Tag: "1.25"},
+	}
+	images := model.ImageComparison{Changed: []model.ImageChange{nginx}}
+
+	actual := diff.Impact(values, userValues, manifests, images)
+	assert.Equal(t, model.UpgradeImpact{
+		Values: []model.ValueImpact{
+			{Path: "/image", Value: map[string]interface{}{"pullPolicy": "Always"}, RenamedTo: "/controller/image"},
+			{Path: "/legacy", Value: map[string]interface{}{"enabled": true}},
+		},
+		Recreated: []model.RecreateImpact{
+			{
+				ObjectIdentity: model.ObjectIdentity{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				Manifest:       "deployment.yaml",
+				Fields:         []string{"/spec/selector"},
+			},
+			{
+				ObjectIdentity: model.ObjectIdentity{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db"},
+				Manifest:       "statefulset.yaml",
+				Fields:         []string{"/spec/volumeClaimTemplates"},
+			},
+		},
+		RemovedResources: []model.ObjectIdentity{{APIVersion: "v1", Kind: "ConfigMap", Name: "legacy"}},
+		ImageChanges:     []model.ImageChange{nginx},
+		AddedImages:      []model.ContainerImage{},
+	}, actual)
+}
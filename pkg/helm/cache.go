@@ -0,0 +1,297 @@
+package helm
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const (
+	defaultLoadedCharts = 32
+	indexTTL            = 10 * time.Minute
+)
+
+type cachedIndex struct {
+	index     *repo.IndexFile
+	fetchedAt time.Time
+}
+
+type loadedChart struct {
+	key   string
+	chart *chart.Chart
+}
+
+// chartCache stores every downloaded chart archive on disk under a key derived
+// from the repo URL, chart name, version and archive digest, and keeps the most
+// recently used charts loaded in memory.
+type chartCache struct {
+	dir      string
+	capacity int
+	getters  getter.Providers
+
+	mu      sync.Mutex
+	indexes map[string]cachedIndex
+	charts  map[string]*list.Element
+	order   *list.List
+}
+
+func newChartCache(dir string, capacity int, getters getter.Providers) *chartCache {
+	return &chartCache{
+		dir:      dir,
+		capacity: capacity,
+		getters:  getters,
+		indexes:  map[string]cachedIndex{},
+		charts:   map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (c *chartCache) Load(repoURL, chartName, chartVersion string) (*chart.Chart, error) {
+	index, err := c.index(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	chartRef, err := index.Get(chartName, chartVersion)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find %s:%s in %s: %w", chartName, chartVersion, repoURL, err)
+	}
+
+	if len(chartRef.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s:%s in %s has no download url", chartName, chartVersion, repoURL)
+	}
+
+	key := archiveKey(repoURL, chartName, chartRef.Version, chartRef.Digest)
+	if loaded, ok := c.get(key); ok {
+		return loaded, nil
+	}
+
+	archive, err := c.archive(repoURL, chartRef, key)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(key, loaded)
+	return loaded, nil
+}
+
+func (c *chartCache) archive(repoURL string, chartRef *repo.ChartVersion, key string) ([]byte, error) {
+	archivePath := filepath.Join(c.dir, "archives", key+".tgz")
+
+	archive, err := os.ReadFile(archivePath)
+	if err == nil && matchDigest(archive, chartRef.Digest) {
+		return archive, nil
+	}
+
+	chartURL, err := repo.ResolveReferenceURL(repoURL, chartRef.URLs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("downloading %s:%s from %s\n", chartRef.Name, chartRef.Version, chartURL)
+	archive, err = c.download(chartURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !matchDigest(archive, chartRef.Digest) {
+		return nil, fmt.Errorf("digest mismatch for %s: expected %s", chartURL, chartRef.Digest)
+	}
+
+	err = writeFileAtomic(archivePath, archive)
+	if err != nil {
+		log.Printf("failed to store chart archive %s: %s\n", archivePath, err)
+	}
+
+	return archive, nil
+}
+
+func (c *chartCache) index(repoURL string) (*repo.IndexFile, error) {
+	c.mu.Lock()
+	cached, ok := c.indexes[repoURL]
+	c.mu.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < indexTTL {
+		return cached.index, nil
+	}
+
+	indexPath := filepath.Join(c.dir, "indexes", digestOf([]byte(repoURL))+".yaml")
+	content, err := c.download(strings.TrimSuffix(repoURL, "/") + "/index.yaml")
+	if err == nil {
+		err = writeFileAtomic(indexPath, content)
+	}
+
+	if err != nil {
+		if _, statErr := os.Stat(indexPath); statErr != nil {
+			return nil, err
+		}
+		log.Printf("failed to refresh index of %s, using stored copy: %s\n", repoURL, err)
+	}
+
+	index, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.indexes[repoURL] = cachedIndex{index: index, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return index, nil
+}
+
+func (c *chartCache) download(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := c.getters.ByScheme(u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.Bytes(), nil
+}
+
+func (c *chartCache) get(key string) (*chart.Chart, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.charts[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(loadedChart).chart, true
+}
+
+func (c *chartCache) put(key string, loaded *chart.Chart) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.charts[key]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.charts[key] = c.order.PushFront(loadedChart{key: key, chart: loaded})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.charts, oldest.Value.(loadedChart).key)
+	}
+}
+
+func archiveKey(repoURL, chartName, chartVersion, digest string) string {
+	return digestOf([]byte(strings.Join([]string{repoURL, chartName, chartVersion, digest}, "\n")))
+}
+
+func matchDigest(content []byte, digest string) bool {
+	return digest == "" || strings.TrimPrefix(digest, "sha256:") == digestOf(content)
+}
+
+func digestOf(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// cloneChart copies the parts of a cached chart that an install run mutates
+// while resolving dependencies, so a render never changes the cached chart.
+func cloneChart(c *chart.Chart) *chart.Chart {
+	clone := *c
+
+	if c.Metadata != nil {
+		metadata := *c.Metadata
+		metadata.Dependencies = make([]*chart.Dependency, 0, len(c.Metadata.Dependencies))
+		for _, d := range c.Metadata.Dependencies {
+			dependency := *d
+			metadata.Dependencies = append(metadata.Dependencies, &dependency)
+		}
+		clone.Metadata = &metadata
+	}
+
+	clone.Values = copyValues(c.Values)
+
+	dependencies := make([]*chart.Chart, 0, len(c.Dependencies()))
+	for _, d := range c.Dependencies() {
+		dependencies = append(dependencies, cloneChart(d))
+	}
+	clone.SetDependencies(dependencies...)
+
+	return &clone
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	copied := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		copied[k] = copyValue(v)
+	}
+
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyValues(v)
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,241 @@
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// chartRepo serves an index and the archive of a single chart version, and
+// counts the requests of each.
+type chartRepo struct {
+	server   *httptest.Server
+	archive  []byte
+	index    []byte
+	down     atomic.Bool
+	indexes  atomic.Int32
+	archives atomic.Int32
+}
+
+func newChartRepo(t *testing.T) *chartRepo {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: "1.0.0"},
+		Raw:      []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("image:\n  tag: \"1.0\"\n  pullSecrets:\n    - registry\n")}},
+		Files:    []*chart.File{{Name: "crds/backups.yaml", Data: []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: backups.acme.io\n---\napiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: restores.acme.io\n")}},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\n")},
+			{Name: "templates/backup.yaml", Data: []byte("{{- if .Capabilities.APIVersions.Has \"acme.io/v1/Backup\" }}\napiVersion: acme.io/v1\nkind: Backup\nmetadata:\n  name: {{ .Release.Name }}\n  annotations:\n    kube-version: {{ .Capabilities.KubeVersion.Version }}\n{{- end }}\n")},
+		},
+	}
+	archivePath, err := chartutil.Save(c, t.TempDir())
+	assert.NoError(t, err)
+
+	r := &chartRepo{}
+	r.archive, err = os.ReadFile(archivePath)
+	assert.NoError(t, err)
+
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/index.yaml":
+			r.indexes.Add(1)
+		case "/app-1.0.0.tgz":
+			r.archives.Add(1)
+		}
+
+		if r.down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/index.yaml":
+			w.Write(r.index)
+		case "/app-1.0.0.tgz":
+			w.Write(r.archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.server.Close)
+
+	index := repo.NewIndexFile()
+	err = index.MustAdd(c.Metadata, "app-1.0.0.tgz", r.server.URL, digestOf(r.archive))
+	assert.NoError(t, err)
+	r.index, err = k8syaml.Marshal(index)
+	assert.NoError(t, err)
+
+	return r
+}
+
+func newTestChartCache(dir string, capacity int) *chartCache {
+	return newChartCache(dir, capacity, getter.All(settings))
+}
+
+func Test_chartCache_put(t *testing.T) {
+	c := newTestChartCache(t.TempDir(), 2)
+	a, b, d := &chart.Chart{}, &chart.Chart{}, &chart.Chart{}
+
+	c.put("a", a)
+	c.put("b", b)
+	_, ok := c.get("a")
+	assert.True(t, ok)
+
+	// b is now the least recently used chart.
+	c.put("d", d)
+
+	_, ok = c.get("b")
+	assert.False(t, ok)
+	loaded, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Same(t, a, loaded)
+	loaded, ok = c.get("d")
+	assert.True(t, ok)
+	assert.Same(t, d, loaded)
+	assert.Equal(t, 2, c.order.Len())
+}
+
+func Test_chartCache_Load(t *testing.T) {
+	r := newChartRepo(t)
+	dir := t.TempDir()
+
+	loaded, err := newTestChartCache(dir, defaultLoadedCharts).Load(r.server.URL, "app", "1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "app", loaded.Name())
+	assert.Equal(t, int32(1), r.archives.Load())
+
+	key := archiveKey(r.server.URL, "app", "1.0.0", digestOf(r.archive))
+	archivePath := filepath.Join(dir, "archives", key+".tgz")
+	stored, err := os.ReadFile(archivePath)
+	assert.NoError(t, err)
+	assert.Equal(t, r.archive, stored)
+
+	// A new cache reads the archive from disk.
+	_, err = newTestChartCache(dir, defaultLoadedCharts).Load(r.server.URL, "app", "1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), r.archives.Load())
+
+	// A corrupted archive on disk does not match the digest and is fetched
+	// again.
+	err = os.WriteFile(archivePath, []byte("corrupted"), 0644)
+	assert.NoError(t, err)
+
+	loaded, err = newTestChartCache(dir, defaultLoadedCharts).Load(r.server.URL, "app", "1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "app", loaded.Name())
+	assert.Equal(t, int32(2), r.archives.Load())
+	stored, err = os.ReadFile(archivePath)
+	assert.NoError(t, err)
+	assert.Equal(t, r.archive, stored)
+
+	// An archive served with another digest is rejected.
+	r.archive = append([]byte{}, r.archive...)
+	r.archive[len(r.archive)-1] ^= 0xff
+	err = os.Remove(archivePath)
+	assert.NoError(t, err)
+
+	_, err = newTestChartCache(dir, defaultLoadedCharts).Load(r.server.URL, "app", "1.0.0")
+	assert.ErrorContains(t, err, "digest mismatch")
+	_, err = os.Stat(archivePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_chartCache_index(t *testing.T) {
+	r := newChartRepo(t)
+	dir := t.TempDir()
+	c := newTestChartCache(dir, defaultLoadedCharts)
+
+	_, err := c.index(r.server.URL)
+	assert.NoError(t, err)
+	_, err = c.index(r.server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), r.indexes.Load())
+
+	// Once the TTL passes the index is fetched again, and the stored copy is
+	// used when the repo cannot be reached.
+	cached := c.indexes[r.server.URL]
+	cached.fetchedAt = time.Now().Add(-indexTTL)
+	c.indexes[r.server.URL] = cached
+	r.down.Store(true)
+
+	index, err := c.index(r.server.URL)
+	assert.NoError(t, err)
+	assert.True(t, index.Has("app", "1.0.0"))
+	assert.Equal(t, int32(2), r.indexes.Load())
+
+	// Without a stored copy the error is returned.
+	_, err = newTestChartCache(t.TempDir(), defaultLoadedCharts).index(r.server.URL)
+	assert.Error(t, err)
+}
+
+func Test_helm_GetValues(t *testing.T) {
+	r := newChartRepo(t)
+	h := helm{charts: newTestChartCache(t.TempDir(), defaultLoadedCharts)}
+
+	values, err := h.GetValues(r.server.URL, "app", "1.0.0")
+	assert.NoError(t, err)
+	values["image"].(map[string]interface{})["tag"] = "changed"
+	values["image"].(map[string]interface{})["pullSecrets"].([]interface{})[0] = "changed"
+	values["replicaCount"] = 3
+
+	values, err = h.GetValues(r.server.URL, "app", "1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"image": map[string]interface{}{"tag": "1.0", "pullSecrets": []interface{}{"registry"}}}, values)
+}
+
+func Test_cloneChart(t *testing.T) {
+	dependency := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "db"},
+		Values:   map[string]interface{}{"port": 5432},
+	}
+	original := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:         "app",
+			Dependencies: []*chart.Dependency{{Name: "db", Condition: "db.enabled"}},
+		},
+		Values: map[string]interface{}{"db": map[string]interface{}{"enabled": true}},
+	}
+	original.SetDependencies(dependency)
+
+	clone := cloneChart(original)
+	clone.Values["db"].(map[string]interface{})["enabled"] = false
+	clone.Metadata.Dependencies[0].Enabled = true
+	clone.Metadata.Dependencies = append(clone.Metadata.Dependencies, &chart.Dependency{Name: "cache"})
+	clone.Dependencies()[0].Values["port"] = 3306
+	clone.SetDependencies()
+
+	assert.Equal(t, map[string]interface{}{"db": map[string]interface{}{"enabled": true}}, original.Values)
+	assert.Len(t, original.Metadata.Dependencies, 1)
+	assert.False(t, original.Metadata.Dependencies[0].Enabled)
+	assert.Len(t, original.Dependencies(), 1)
+	assert.Equal(t, map[string]interface{}{"port": 5432}, original.Dependencies()[0].Values)
+}
+
+func Test_writeFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "indexes", "index.yaml")
+
+	err := writeFileAtomic(path, []byte("first"))
+	assert.NoError(t, err)
+	err = writeFileAtomic(path, []byte("second"))
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "index.yaml", entries[0].Name())
+}
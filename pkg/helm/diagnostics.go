@@ -0,0 +1,123 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"chart-viewer/pkg/model"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const snippetContext = 2
+
+var renderErrorPatterns = []struct {
+	phase string
+	re    *regexp.Regexp
+}{
+	{phase: "parse", re: regexp.MustCompile(`parse error at \((?P<path>[^:()\s]+):(?P<line>\d+)\): (?P<message>.*)`)},
+	{phase: "execute", re: regexp.MustCompile(`execution error at \((?P<path>[^:()\s]+):(?P<line>\d+):(?P<column>\d+)\): (?P<message>.*)`)},
+	{phase: "execute", re: regexp.MustCompile(`template: (?P<path>[^:()\s]+):(?P<line>\d+):(?P<column>\d+): (?:executing "[^"]*" )?(?P<message>.*)`)},
+	{phase: "yaml", re: regexp.MustCompile(`YAML parse error on (?P<path>[^:()\s]+): (?P<message>.*?(?:line (?P<line>\d+).*)?)$`)},
+}
+
+// renderError turns the error returned by a helm install run into a
+// model.RenderError pointing to the failing template, or returns it unchanged
+// when it does not come from a template.
+func renderError(err error, c *chart.Chart) error {
+	for _, pattern := range renderErrorPatterns {
+		submatch := pattern.re.FindStringSubmatch(err.Error())
+		if len(submatch) == 0 {
+			continue
+		}
+
+		group := func(name string) string {
+			index := pattern.re.SubexpIndex(name)
+			if index < 0 {
+				return ""
+			}
+			return submatch[index]
+		}
+
+		diagnostic := model.RenderDiagnostic{
+			Phase:    pattern.phase,
+			Template: strings.Join(strings.Split(group("path"), "/")[1:], "/"),
+			Message:  group("message"),
+		}
+		diagnostic.Line, _ = strconv.Atoi(group("line"))
+		diagnostic.Column, _ = strconv.Atoi(group("column"))
+
+		// YAML errors report the line of the rendered document, which does
+		// not match the template source.
+		if pattern.phase != "yaml" {
+			diagnostic.Snippet = snippet(findTemplate(c, group("path")), diagnostic.Line)
+		}
+
+		return &model.RenderError{
+			Message:     err.Error(),
+			Diagnostics: []model.RenderDiagnostic{diagnostic},
+		}
+	}
+
+	return err
+}
+
+func findTemplate(c *chart.Chart, path string) string {
+	segments := strings.Split(path, "/")
+	if c == nil || len(segments) < 2 || segments[0] != c.Name() {
+		return ""
+	}
+
+	if len(segments) > 3 && segments[1] == "charts" {
+		for _, dependency := range c.Dependencies() {
+			if dependency.Name() == segments[2] {
+				return findTemplate(dependency, strings.Join(segments[2:], "/"))
+			}
+		}
+		return ""
+	}
+
+	name := strings.Join(segments[1:], "/")
+	for _, t := range c.Templates {
+		if t.Name == name {
+			return string(t.Data)
+		}
+	}
+
+	return ""
+}
+
+func snippet(content string, line int) string {
+	if content == "" || line <= 0 {
+		return ""
+	}
+
+	lines := strings.Split(content, "\n")
+	if line > len(lines) {
+		return ""
+	}
+
+	first := line - snippetContext
+	if first < 1 {
+		first = 1
+	}
+
+	last := line + snippetContext
+	if last > len(lines) {
+		last = len(lines)
+	}
+
+	width := len(strconv.Itoa(last))
+	var b strings.Builder
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %*d | %s\n", marker, width, i, lines[i-1])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package helm
+
+import (
+	"errors"
+	"testing"
+
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func Test_renderError(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "demo"},
+		Templates: []*chart.File{
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Values.foo.bar }}\ndata: {}\n"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "should parse execution error with line and column",
+			err:  errors.New(`template: demo/templates/configmap.yaml:4:18: executing "demo/templates/configmap.yaml" at <.Values.foo.bar>: nil pointer evaluating interface {}.bar`),
+			want: &model.RenderError{
+				Message: `template: demo/templates/configmap.yaml:4:18: executing "demo/templates/configmap.yaml" at <.Values.foo.bar>: nil pointer evaluating interface {}.bar`,
+				Diagnostics: []model.RenderDiagnostic{
+					{
+						Phase:    "execute",
+						Template: "templates/configmap.yaml",
+						Line:     4,
+						Column:   18,
+						Message:  "at <.Values.foo.bar>: nil pointer evaluating interface {}.bar",
+						Snippet:  "  2 | kind: ConfigMap\n  3 | metadata:\n> 4 |   name: {{ .Values.foo.bar }}\n  5 | data: {}\n  6 | \n",
+					},
+				},
+			},
+		},
+		{
+			name: "should parse fail function error",
+			err:  errors.New(`execution error at (demo/templates/configmap.yaml:1:3): boom`),
+			want: &model.RenderError{
+				Message: `execution error at (demo/templates/configmap.yaml:1:3): boom`,
+				Diagnostics: []model.RenderDiagnostic{
+					{
+						Phase:    "execute",
+						Template: "templates/configmap.yaml",
+						Line:     1,
+						Column:   3,
+						Message:  "boom",
+						Snippet:  "> 1 | apiVersion: v1\n  2 | kind: ConfigMap\n  3 | metadata:\n",
+					},
+				},
+			},
+		},
+		{
+			name: "should parse template parse error",
+			err:  errors.New(`parse error at (demo/templates/configmap.yaml:4): function "nosuchfn" not defined`),
+			want: &model.RenderError{
+				Message: `parse error at (demo/templates/configmap.yaml:4): function "nosuchfn" not defined`,
+				Diagnostics: []model.RenderDiagnostic{
+					{
+						Phase:    "parse",
+						Template: "templates/configmap.yaml",
+						Line:     4,
+						Message:  `function "nosuchfn" not defined`,
+						Snippet:  "  2 | kind: ConfigMap\n  3 | metadata:\n> 4 |   name: {{ .Values.foo.bar }}\n  5 | data: {}\n  6 | \n",
+					},
+				},
+			},
+		},
+		{
+			name: "should parse yaml error without snippet",
+			err:  errors.New(`YAML parse error on demo/templates/configmap.yaml: error converting YAML to JSON: yaml: line 5: mapping values are not allowed in this context`),
+			want: &model.RenderError{
+				Message: `YAML parse error on demo/templates/configmap.yaml: error converting YAML to JSON: yaml: line 5: mapping values are not allowed in this context`,
+				Diagnostics: []model.RenderDiagnostic{
+					{
+						Phase:    "yaml",
+						Template: "templates/configmap.yaml",
+						Line:     5,
+						Message:  "error converting YAML to JSON: yaml: line 5: mapping values are not allowed in this context",
+					},
+				},
+			},
+		},
+		{
+			name: "should return the error unchanged when it is not a template error",
+			err:  errors.New("cannot find demo:0.1.0"),
+			want: errors.New("cannot find demo:0.1.0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderError(tt.err, c))
+		})
+	}
+}
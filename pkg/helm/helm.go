@@ -10,12 +10,14 @@ import (
 	"strings"
 
 	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/postrender"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
@@ -25,58 +27,41 @@ type Repository interface {
 }
 
 type helm struct {
-	client     *action.Install
 	repository Repository
+	charts     *chartCache
 }
 
 var settings = cli.New()
 
+var manifestNameRegex = regexp.MustCompile("# Source: [^/]+/(.+)")
+
 func debug(format string, v ...interface{}) {}
 
-func NewHelmClient(repository Repository) helm {
-	actionConfig := new(action.Configuration)
-	err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), "", debug)
-	if err != nil {
-		panic(err)
+func NewHelmClient(repository Repository, cacheDir string) helm {
+	if cacheDir == "" {
+		cacheDir = filepath.Join(settings.RepositoryCache, "chart-viewer")
 	}
 
-	client := action.NewInstall(actionConfig)
-	client.DryRun = true
-	client.ClientOnly = true
-	client.UseReleaseName = true
-
 	return helm{
-		client:     client,
 		repository: repository,
+		charts:     newChartCache(cacheDir, defaultLoadedCharts, getter.All(settings)),
 	}
 }
 
 func (h helm) GetValues(chartUrl, chartName, chartVersion string) (map[string]interface{}, error) {
-	log.Printf("getting %s:%s from remote\n", chartName, chartVersion)
+	log.Printf("getting %s:%s values\n", chartName, chartVersion)
 
-	h.client.ChartPathOptions.Version = chartVersion
-	h.client.ReleaseName = chartName
-	h.client.RepoURL = chartUrl
-	cp, err := h.client.ChartPathOptions.LocateChart(chartName, settings)
+	chartRequested, err := h.charts.Load(chartUrl, chartName, chartVersion)
 	if err != nil {
 		return nil, err
 	}
 
-	chartRequested, _ := loader.Load(cp)
-
-	return chartRequested.Values, nil
+	// The chart is shared with every other caller through the cache.
+	return copyValues(chartRequested.Values), nil
 }
 
 func (h helm) GetTemplates(chartUrl, chartName, chartVersion string) ([]model.Template, error) {
-	h.client.ChartPathOptions.Version = chartVersion
-	h.client.ReleaseName = chartName
-	h.client.RepoURL = chartUrl
-	cp, err := h.client.ChartPathOptions.LocateChart(chartName, settings)
-	if err != nil {
-		return nil, err
-	}
-
-	chartRequested, err := loader.Load(cp)
+	chartRequested, err := h.charts.Load(chartUrl, chartName, chartVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -95,41 +80,70 @@ func (h helm) GetTemplates(chartUrl, chartName, chartVersion string) ([]model.Te
 	return templateStrings, nil
 }
 
-func (h helm) RenderManifest(chartUrl, chartName, chartVersion string, valuesFileLocation string) ([]model.Manifest, error) {
-	h.client.ChartPathOptions.Version = chartVersion
-	h.client.ReleaseName = chartName
-	h.client.RepoURL = chartUrl
-	cp, err := h.client.ChartPathOptions.LocateChart(chartName, settings)
+// GetLocalTemplates reads the templates of a chart directory or archive on
+// disk, for the commands that check charts outside of a repository.
+func GetLocalTemplates(chartPath string) ([]model.Template, error) {
+	chartRequested, err := loader.Load(chartPath)
 	if err != nil {
 		return nil, err
 	}
 
-	chartRequested, err := loader.Load(cp)
-
-	valueOption := &values.Options{
-		ValueFiles: []string{valuesFileLocation},
+	var templates []model.Template
+	for _, t := range chartRequested.Templates {
+		templates = append(templates, model.Template{
+			Name:    t.Name,
+			Content: string(t.Data),
+		})
 	}
 
-	p := getter.All(settings)
-	vals, err := valueOption.MergeValues(p)
+	return templates, nil
+}
+
+// RenderManifest renders the chart with the capabilities of the given
+// Kubernetes release, or with the Helm defaults when it is nil.
+func (h helm) RenderManifest(chartUrl, chartName, chartVersion, releaseName string, values map[string]interface{}, options model.RenderOptions, kubeAPIVersion *model.KubernetesAPIVersion) (model.RenderResult, error) {
+	cachedChart, err := h.charts.Load(chartUrl, chartName, chartVersion)
 	if err != nil {
-		return nil, err
+		return model.RenderResult{}, err
 	}
 
-	rel, err := h.client.Run(chartRequested, vals)
+	client, err := newInstall(releaseName)
 	if err != nil {
-		return nil, err
+		return model.RenderResult{}, err
 	}
+	client.DisableHooks = options.SkipHooks
 
-	var manifests bytes.Buffer
-	fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
+	if kubeAPIVersion != nil {
+		err = setCapabilities(client, *kubeAPIVersion)
+		if err != nil {
+			return model.RenderResult{}, err
+		}
+	}
 
-	if !h.client.DisableHooks {
-		for _, h := range rel.Hooks {
-			fmt.Fprintln(&manifests, fmt.Sprintf("---\n # Source: %s\n%s", h.Path, h.Manifest))
+	// The pipeline runs through the helm post-render hook, which only covers
+	// the manifests, so the hooks go through it separately below.
+	var postRenderer *postrender.PostRenderer
+	if options.PostRender != nil {
+		postRenderer, err = postrender.New(*options.PostRender)
+		if err != nil {
+			return model.RenderResult{}, err
 		}
+		client.PostRenderer = postRenderer
+	}
+
+	rel, err := client.Run(cloneChart(cachedChart), values)
+	if err != nil {
+		return model.RenderResult{}, renderError(err, cachedChart)
+	}
+
+	mergedValues, err := chartutil.CoalesceValues(cloneChart(cachedChart), values)
+	if err != nil {
+		return model.RenderResult{}, err
 	}
 
+	var manifests bytes.Buffer
+	fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
+
 	splitManifests := releaseutil.SplitManifests(manifests.String())
 	manifestsKeys := make([]string, 0, len(splitManifests))
 	for k := range splitManifests {
@@ -143,24 +157,222 @@ func (h helm) RenderManifest(chartUrl, chartName, chartVersion string, valuesFil
 	for _, manifestKey := range manifestsKeys {
 		manifest := splitManifests[manifestKey]
 
-		manifestNameRegex := regexp.MustCompile("# Source: [^/]+/(.+)")
 		submatch := manifestNameRegex.FindStringSubmatch(manifest)
 		if len(submatch) == 0 {
 			continue
 		}
-		manifestName := submatch[1]
-		manifestPath := filepath.Join(strings.Split(manifestName, "/")[1:]...)
-		manifestPathSplit := strings.Split(manifestPath, "/")
 
-		if manifestPathSplit[0] == "tests" {
-			continue
+		finalManifests = append(finalManifests, model.Manifest{
+			Name:     manifestPath(submatch[1]),
+			Template: submatch[1],
+			Content:  manifest,
+		})
+	}
+
+	if !client.DisableHooks {
+		for _, hook := range rel.Hooks {
+			manifest := strings.TrimSpace(fmt.Sprintf("# Source: %s\n%s", hook.Path, hook.Manifest))
+			if postRenderer != nil {
+				manifest, err = postRenderer.Transform(manifest)
+				if err != nil {
+					return model.RenderResult{}, err
+				}
+			}
+
+			submatch := manifestNameRegex.FindStringSubmatch(manifest)
+			if len(submatch) == 0 {
+				continue
+			}
+
+			var events []string
+			for _, e := range hook.Events {
+				events = append(events, string(e))
+			}
+
+			var deletePolicies []string
+			for _, p := range hook.DeletePolicies {
+				deletePolicies = append(deletePolicies, string(p))
+			}
+
+			finalManifests = append(finalManifests, model.Manifest{
+				Name:     manifestPath(submatch[1]),
+				Template: submatch[1],
+				Content:  manifest,
+				Hook: &model.ManifestHook{
+					Events:         events,
+					Weight:         hook.Weight,
+					DeletePolicies: deletePolicies,
+				},
+			})
 		}
+	}
 
-		finalManifests = append(finalManifests, model.Manifest{
-			Name:    manifestPath,
-			Content: manifest,
+	// Helm does not pass the CRDs to the post-renderer, they run through the
+	// pipeline here so they get the same labels, annotations and patches.
+	var crds []model.Manifest
+	for _, crd := range cachedChart.CRDObjects() {
+		template := strings.Join(strings.Split(crd.Filename, "/")[1:], "/")
+		content := string(crd.File.Data)
+		if postRenderer != nil {
+			content, err = transformDocuments(postRenderer, content)
+			if err != nil {
+				return model.RenderResult{}, err
+			}
+		}
+
+		crds = append(crds, model.Manifest{
+			Name:     manifestPath(template),
+			Template: template,
+			Content:  content,
 		})
 	}
 
-	return finalManifests, err
+	if postRenderer != nil {
+		err = postRenderer.CheckMatched()
+		if err != nil {
+			return model.RenderResult{}, err
+		}
+	}
+
+	finalManifests, err = filterManifests(finalManifests, options)
+	if err != nil {
+		return model.RenderResult{}, err
+	}
+
+	return model.RenderResult{
+		Manifests: finalManifests,
+		CRDs:      crds,
+		Notes:     rel.Info.Notes,
+		Values:    mergedValues.AsMap(),
+	}, nil
+}
+
+func newInstall(releaseName string) (*action.Install, error) {
+	actionConfig := new(action.Configuration)
+	err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), "", debug)
+	if err != nil {
+		return nil, err
+	}
+
+	client := action.NewInstall(actionConfig)
+	client.DryRun = true
+	client.ClientOnly = true
+	client.UseReleaseName = true
+	client.ReleaseName = releaseName
+
+	return client, nil
+}
+
+// setCapabilities sets the Kubernetes version and API versions the templates
+// see, like helm template --kube-version and --api-versions. Helm adds the
+// API versions to its defaults rather than replacing them.
+func setCapabilities(client *action.Install, kubeAPIVersion model.KubernetesAPIVersion) error {
+	kubeVersion, err := chartutil.ParseKubeVersion(kubeAPIVersion.KubeVersion)
+	if err != nil {
+		return err
+	}
+
+	apiVersions := append(chartutil.VersionSet{}, kubeAPIVersion.APIVersions...)
+	for _, r := range kubeAPIVersion.Resources {
+		apiVersions = append(apiVersions, fmt.Sprintf("%s/%s", r.APIVersion(), r.Kind))
+	}
+
+	client.KubeVersion = kubeVersion
+	client.APIVersions = apiVersions
+
+	return nil
+}
+
+// transformDocuments runs the post-render pipeline on every document of a
+// file, which may hold several CRDs.
+func transformDocuments(postRenderer *postrender.PostRenderer, content string) (string, error) {
+	documents := releaseutil.SplitManifests(content)
+	keys := make([]string, 0, len(documents))
+	for k := range documents {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	transformed := make([]string, 0, len(keys))
+	for _, k := range keys {
+		document, err := postRenderer.Transform(documents[k])
+		if err != nil {
+			return "", err
+		}
+		transformed = append(transformed, document)
+	}
+
+	return strings.Join(transformed, "\n---\n") + "\n", nil
+}
+
+// manifestPath turns a chart relative template path like
+// templates/deployment.yaml into the name the manifest is listed under.
+func manifestPath(templatePath string) string {
+	return filepath.Join(strings.Split(templatePath, "/")[1:]...)
+}
+
+func filterManifests(manifests []model.Manifest, options model.RenderOptions) ([]model.Manifest, error) {
+	var filtered []model.Manifest
+	for _, m := range manifests {
+		if !options.IncludeTests && isTest(m) {
+			continue
+		}
+
+		if len(options.ShowOnly) != 0 && !matchAny(options.ShowOnly, m.Name) {
+			continue
+		}
+
+		filtered = append(filtered, m)
+	}
+
+	for _, pattern := range options.ShowOnly {
+		found := false
+		for _, m := range filtered {
+			if matchAny([]string{pattern}, m.Name) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: could not find template %s in chart", model.ErrInvalidShowOnly, pattern)
+		}
+	}
+
+	return filtered, nil
+}
+
+func isTest(manifest model.Manifest) bool {
+	if strings.Split(manifest.Name, "/")[0] == "tests" {
+		return true
+	}
+
+	if manifest.Hook == nil {
+		return false
+	}
+
+	for _, e := range manifest.Hook.Events {
+		if e == string(release.HookTest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchAny matches the manifest name against show-only patterns, which may be
+// written either relative to the templates directory or including it.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "templates/")
+		if pattern == name {
+			return true
+		}
+
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,74 @@
+package helm
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_filterManifests(t *testing.T) {
+	manifests := []model.Manifest{
+		{Name: "deployment.yaml", Content: "kind: Deployment"},
+		{Name: "service.yaml", Content: "kind: Service"},
+		{Name: "tests/test-connection.yaml", Content: "kind: Pod"},
+	}
+
+	filtered, err := filterManifests(manifests, model.RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, manifests[:2], filtered)
+
+	filtered, err = filterManifests(manifests, model.RenderOptions{IncludeTests: true, ShowOnly: []string{"tests/*"}})
+	assert.NoError(t, err)
+	assert.Equal(t, manifests[2:], filtered)
+
+	_, err = filterManifests(manifests, model.RenderOptions{ShowOnly: []string{"service.yaml", "ingress.yaml"}})
+	assert.ErrorIs(t, err, model.ErrInvalidShowOnly)
+	assert.EqualError(t, err, "invalid show_only: could not find template ingress.yaml in chart")
+}
+
+func Test_helm_RenderManifest_capabilities(t *testing.T) {
+	r := newChartRepo(t)
+	h := helm{charts: newTestChartCache(t.TempDir(), defaultLoadedCharts)}
+
+	rendered, err := h.RenderManifest(r.server.URL, "app", "1.0.0", "web", nil, model.RenderOptions{}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, rendered.Manifests, 1)
+	assert.Equal(t, "configmap.yaml", rendered.Manifests[0].Name)
+
+	kubeAPIVersion := model.KubernetesAPIVersion{
+		KubeVersion: "1.16",
+		APIVersions: []string{"acme.io/v1"},
+		Resources:   []model.GroupVersionKind{{Group: "acme.io", Version: "v1", Kind: "Backup"}},
+	}
+	rendered, err = h.RenderManifest(r.server.URL, "app", "1.0.0", "web", nil, model.RenderOptions{}, &kubeAPIVersion)
+	assert.NoError(t, err)
+	assert.Len(t, rendered.Manifests, 2)
+	assert.Equal(t, "backup.yaml", rendered.Manifests[1].Name)
+	assert.Contains(t, rendered.Manifests[1].Content, "kube-version: v1.16.0")
+
+	_, err = h.RenderManifest(r.server.URL, "app", "1.0.0", "web", nil, model.RenderOptions{}, &model.KubernetesAPIVersion{KubeVersion: "latest"})
+	assert.Error(t, err)
+}
+
+func Test_helm_RenderManifest_postRenderCRDs(t *testing.T) {
+	r := newChartRepo(t)
+	h := helm{charts: newTestChartCache(t.TempDir(), defaultLoadedCharts)}
+
+	options := model.RenderOptions{PostRender: &model.PostRender{
+		Namespace: "apps",
+		Labels:    map[string]string{"team": "platform"},
+		Patches: []model.Patch{{
+			Type:   "json6902",
+			Target: &model.PatchTarget{Kind: "CustomResourceDefinition", Name: "restores.acme.io"},
+			Patch:  `[{"op": "add", "path": "/metadata/annotations", "value": {"restore": "enabled"}}]`,
+		}},
+	}}
+	rendered, err := h.RenderManifest(r.server.URL, "app", "1.0.0", "web", nil, options, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Manifest{{
+		Name:     "backups.yaml",
+		Template: "crds/backups.yaml",
+		Content:  "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  labels:\n    team: platform\n  name: backups.acme.io\n---\napiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  annotations:\n    restore: enabled\n  labels:\n    team: platform\n  name: restores.acme.io\n",
+	}}, rendered.CRDs)
+}
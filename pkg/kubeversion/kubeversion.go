@@ -0,0 +1,393 @@
+package kubeversion
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"chart-viewer/pkg/model"
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatAuto         = "auto"
+	FormatAPIResources = "api-resources"
+	FormatDiscovery    = "discovery"
+	FormatOpenAPI      = "openapi"
+)
+
+var (
+	releaseVersion = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+	headerColumn   = regexp.MustCompile(`\S+`)
+)
+
+// Document is the content of a file describing the API resources served by a
+// Kubernetes release.
+type Document struct {
+	KubeVersion string
+	Resources   []model.GroupVersionKind
+}
+
+// discoveryDocument holds the fields of the APIResourceList, APIGroupList,
+// APIGroup and APIVersions documents served under /api and /apis.
+type discoveryDocument struct {
+	GroupVersion string              `yaml:"groupVersion"`
+	Resources    []discoveryResource `yaml:"resources"`
+	Versions     yaml.Node           `yaml:"versions"`
+	Groups       []discoveryDocument `yaml:"groups"`
+	Items        []discoveryDocument `yaml:"items"`
+}
+
+type discoveryResource struct {
+	Name string `yaml:"name"`
+	Kind string `yaml:"kind"`
+}
+
+type openAPIDocument struct {
+	Swagger string `yaml:"swagger"`
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Definitions map[string]openAPISchema `yaml:"definitions"`
+	Components  struct {
+		Schemas map[string]openAPISchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type openAPISchema struct {
+	GroupVersionKinds []model.GroupVersionKind `yaml:"x-kubernetes-group-version-kind"`
+}
+
+// Parse reads saved `kubectl api-resources` output, discovery documents or
+// OpenAPI v2/v3 documents. The Kubernetes version is only known for OpenAPI
+// documents.
+func Parse(content []byte, format string) (Document, error) {
+	if format == FormatAuto || format == "" {
+		format = detectFormat(content)
+	}
+
+	switch format {
+	case FormatAPIResources:
+		return parseAPIResources(content)
+	case FormatDiscovery:
+		return parseDiscovery(content)
+	case FormatOpenAPI:
+		return parseOpenAPI(content)
+	default:
+		return Document{}, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func detectFormat(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("NAME ")) {
+		return FormatAPIResources
+	}
+
+	var probe struct {
+		Swagger string `yaml:"swagger"`
+		OpenAPI string `yaml:"openapi"`
+	}
+	err := yaml.Unmarshal(content, &probe)
+	if err == nil && (probe.Swagger != "" || probe.OpenAPI != "") {
+		return FormatOpenAPI
+	}
+
+	return FormatDiscovery
+}
+
+// parseAPIResources reads the columns of each line at the offsets of the
+// header, since kubectl aligns the table and leaves SHORTNAMES empty for most
+// resources.
+func parseAPIResources(content []byte) (Document, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var offsets map[string][2]int
+	var resources []model.GroupVersionKind
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if offsets == nil {
+			offsets = columnOffsets(line)
+			_, hasAPIVersion := offsets["APIVERSION"]
+			_, hasKind := offsets["KIND"]
+			if !hasAPIVersion || !hasKind {
+				return Document{}, errors.New("api-resources output must have APIVERSION and KIND columns")
+			}
+			continue
+		}
+
+		apiVersion := column(line, offsets["APIVERSION"])
+		kind := column(line, offsets["KIND"])
+		if apiVersion == "" || kind == "" {
+			return Document{}, fmt.Errorf("cannot parse api-resources line %q", line)
+		}
+
+		resources = append(resources, NewGroupVersionKind(apiVersion, kind))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Document{}, err
+	}
+
+	return Document{Resources: resources}, nil
+}
+
+func columnOffsets(header string) map[string][2]int {
+	offsets := map[string][2]int{}
+	matches := headerColumn.FindAllStringIndex(header, -1)
+	for i, m := range matches {
+		end := -1
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		offsets[header[m[0]:m[1]]] = [2]int{m[0], end}
+	}
+
+	return offsets
+}
+
+func column(line string, offset [2]int) string {
+	if offset[0] >= len(line) {
+		return ""
+	}
+
+	if offset[1] < 0 || offset[1] > len(line) {
+		return strings.TrimSpace(line[offset[0]:])
+	}
+
+	return strings.TrimSpace(line[offset[0]:offset[1]])
+}
+
+func parseDiscovery(content []byte) (Document, error) {
+	var documents []discoveryDocument
+
+	var list []discoveryDocument
+	if err := yaml.Unmarshal(content, &list); err == nil {
+		documents = list
+	} else {
+		var document discoveryDocument
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return Document{}, fmt.Errorf("cannot parse discovery document: %w", err)
+		}
+		documents = []discoveryDocument{document}
+	}
+
+	var resources []model.GroupVersionKind
+	for len(documents) != 0 {
+		document := documents[0]
+		documents = append(documents[1:], document.Items...)
+
+		for _, r := range document.Resources {
+			// Subresources such as deployments/scale repeat the kind of
+			// another group version.
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			resources = append(resources, NewGroupVersionKind(document.GroupVersion, r.Kind))
+		}
+
+		if len(document.Resources) == 0 && document.GroupVersion != "" {
+			resources = append(resources, NewGroupVersionKind(document.GroupVersion, ""))
+		}
+
+		documents = append(documents, document.Groups...)
+		for _, v := range groupVersions(document.Versions) {
+			resources = append(resources, NewGroupVersionKind(v, ""))
+		}
+	}
+
+	if len(resources) == 0 {
+		return Document{}, errors.New("discovery document does not contain any group version")
+	}
+
+	return Document{Resources: resources}, nil
+}
+
+// groupVersions reads the versions of an APIVersions document, which lists
+// plain strings, or of an APIGroup document, which lists objects.
+func groupVersions(node yaml.Node) []string {
+	var versions []string
+	if node.Decode(&versions) == nil {
+		return versions
+	}
+
+	var groupVersions []struct {
+		GroupVersion string `yaml:"groupVersion"`
+	}
+	if node.Decode(&groupVersions) != nil {
+		return nil
+	}
+
+	for _, v := range groupVersions {
+		versions = append(versions, v.GroupVersion)
+	}
+
+	return versions
+}
+
+func parseOpenAPI(content []byte) (Document, error) {
+	var document openAPIDocument
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return Document{}, fmt.Errorf("cannot parse openapi document: %w", err)
+	}
+
+	schemas := document.Definitions
+	if document.OpenAPI != "" {
+		schemas = document.Components.Schemas
+	}
+
+	var resources []model.GroupVersionKind
+	for name, schema := range schemas {
+		// Meta types such as DeleteOptions and WatchEvent are declared for
+		// every group version but are not resources.
+		if strings.HasPrefix(name, "io.k8s.apimachinery.pkg.apis.meta.") {
+			continue
+		}
+
+		for _, gvk := range schema.GroupVersionKinds {
+			if strings.HasSuffix(gvk.Kind, "List") {
+				continue
+			}
+			resources = append(resources, gvk)
+		}
+	}
+
+	return Document{
+		KubeVersion: ReleaseVersion(document.Info.Version),
+		Resources:   resources,
+	}, nil
+}
+
+// ReleaseVersion returns the major.minor version of a Kubernetes version such
+// as v1.27.3, or an empty string when it cannot be parsed.
+func ReleaseVersion(version string) string {
+	submatch := releaseVersion.FindStringSubmatch(strings.TrimSpace(version))
+	if len(submatch) == 0 {
+		return ""
+	}
+
+	return submatch[1] + "." + submatch[2]
+}
+
+// Merge adds the resources to the dataset record of the Kubernetes version,
+// or replaces the record when replace is set. The dataset is sorted by
+// Kubernetes version.
+func Merge(dataset []model.KubernetesAPIVersion, kubeVersion string, resources []model.GroupVersionKind, replace bool) []model.KubernetesAPIVersion {
+	index := -1
+	for i, k := range dataset {
+		if k.KubeVersion == kubeVersion {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		dataset = append(dataset, model.KubernetesAPIVersion{KubeVersion: kubeVersion})
+		index = len(dataset) - 1
+	}
+
+	record := dataset[index]
+	if replace {
+		record = model.KubernetesAPIVersion{KubeVersion: kubeVersion}
+	}
+
+	apiVersions := map[string]bool{}
+	for _, v := range record.APIVersions {
+		apiVersions[v] = true
+	}
+
+	kinds := map[model.GroupVersionKind]bool{}
+	for _, r := range record.Resources {
+		kinds[r] = true
+	}
+
+	for _, r := range resources {
+		apiVersions[r.APIVersion()] = true
+		if r.Kind != "" {
+			kinds[r] = true
+		}
+	}
+
+	record.APIVersions = []string{}
+	for v := range apiVersions {
+		record.APIVersions = append(record.APIVersions, v)
+	}
+	sort.Strings(record.APIVersions)
+
+	record.Resources = nil
+	for r := range kinds {
+		record.Resources = append(record.Resources, r)
+	}
+	sort.Slice(record.Resources, func(i, j int) bool {
+		a, b := record.Resources[i], record.Resources[j]
+		if a.APIVersion() != b.APIVersion() {
+			return a.APIVersion() < b.APIVersion()
+		}
+		return a.Kind < b.Kind
+	})
+
+	dataset[index] = record
+	sort.SliceStable(dataset, func(i, j int) bool {
+		return Less(dataset[i].KubeVersion, dataset[j].KubeVersion)
+	})
+
+	return dataset
+}
+
+// Less reports whether Kubernetes version a is older than b.
+func Less(a, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+
+	return va.LessThan(vb)
+}
+
+// Find returns the dataset record of the Kubernetes version, or of the latest
+// version when kubeVersion is empty.
+func Find(dataset []model.KubernetesAPIVersion, kubeVersion string) (model.KubernetesAPIVersion, error) {
+	if kubeVersion == "" && len(dataset) != 0 {
+		latest := dataset[0]
+		for _, k := range dataset[1:] {
+			if Less(latest.KubeVersion, k.KubeVersion) {
+				latest = k
+			}
+		}
+		return latest, nil
+	}
+
+	for _, k := range dataset {
+		if k.KubeVersion == kubeVersion {
+			return k, nil
+		}
+	}
+
+	return model.KubernetesAPIVersion{}, fmt.Errorf("%w: %q", model.ErrUnknownKubeVersion, kubeVersion)
+}
+
+// NewGroupVersionKind splits an apiVersion like apps/v1 into its group and
+// version.
+func NewGroupVersionKind(apiVersion, kind string) model.GroupVersionKind {
+	gvk := model.GroupVersionKind{Version: apiVersion, Kind: kind}
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		gvk.Group = apiVersion[:i]
+		gvk.Version = apiVersion[i+1:]
+	}
+
+	return gvk
+}
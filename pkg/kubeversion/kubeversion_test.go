@@ -0,0 +1,211 @@
+package kubeversion_test
+
+import (
+	"testing"
+
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		format  string
+		want    kubeversion.Document
+		wantErr string
+	}{
+		{
+			name: "should read kubectl api-resources output",
+			content: `NAME                              SHORTNAMES   APIVERSION                        NAMESPACED   KIND
+bindings                                       v1                                true         Binding
+configmaps                        cm           v1                                true         ConfigMap
+deployments                       deploy       apps/v1                           true         Deployment
+ingresses                         ing          networking.k8s.io/v1              true         Ingress
+`,
+			format: kubeversion.FormatAuto,
+			want: kubeversion.Document{
+				Resources: []model.GroupVersionKind{
+					{Version: "v1", Kind: "Binding"},
+					{Version: "v1", Kind: "ConfigMap"},
+					{Group: "apps", Version: "v1", Kind: "Deployment"},
+					{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+				},
+			},
+		},
+		{
+			name: "should fail on api-resources output without api versions",
+			content: `NAME          SHORTNAMES   APIGROUP   NAMESPACED   KIND
+configmaps    cm                      true         ConfigMap
+`,
+			format:  kubeversion.FormatAPIResources,
+			wantErr: "api-resources output must have APIVERSION and KIND columns",
+		},
+		{
+			name: "should read a discovery resource list and skip subresources",
+			content: `{
+				"kind": "APIResourceList",
+				"groupVersion": "apps/v1",
+				"resources": [
+					{"name": "deployments", "kind": "Deployment"},
+					{"name": "deployments/scale", "kind": "Scale"}
+				]
+			}`,
+			format: kubeversion.FormatAuto,
+			want: kubeversion.Document{
+				Resources: []model.GroupVersionKind{
+					{Group: "apps", Version: "v1", Kind: "Deployment"},
+				},
+			},
+		},
+		{
+			name: "should read discovery group lists and core versions",
+			content: `[
+				{"kind": "APIVersions", "versions": ["v1"]},
+				{"kind": "APIGroupList", "groups": [
+					{"name": "batch", "versions": [{"groupVersion": "batch/v1", "version": "v1"}]}
+				]}
+			]`,
+			format: kubeversion.FormatDiscovery,
+			want: kubeversion.Document{
+				Resources: []model.GroupVersionKind{
+					{Version: "v1"},
+					{Group: "batch", Version: "v1"},
+				},
+			},
+		},
+		{
+			name: "should read resources and the Kubernetes version of an OpenAPI v2 document",
+			content: `{
+				"swagger": "2.0",
+				"info": {"title": "Kubernetes", "version": "v1.27.3"},
+				"definitions": {
+					"io.k8s.api.batch.v1.CronJob": {
+						"x-kubernetes-group-version-kind": [{"group": "batch", "kind": "CronJob", "version": "v1"}]
+					},
+					"io.k8s.api.batch.v1.CronJobList": {
+						"x-kubernetes-group-version-kind": [{"group": "batch", "kind": "CronJobList", "version": "v1"}]
+					},
+					"io.k8s.apimachinery.pkg.apis.meta.v1.WatchEvent": {
+						"x-kubernetes-group-version-kind": [{"group": "batch", "kind": "WatchEvent", "version": "v1beta1"}]
+					}
+				}
+			}`,
+			format: kubeversion.FormatAuto,
+			want: kubeversion.Document{
+				KubeVersion: "1.27",
+				Resources: []model.GroupVersionKind{
+					{Group: "batch", Version: "v1", Kind: "CronJob"},
+				},
+			},
+		},
+		{
+			name: "should read resources of an OpenAPI v3 document",
+			content: `openapi: 3.0.0
+info:
+  title: Kubernetes
+  version: unversioned
+components:
+  schemas:
+    io.k8s.api.core.v1.Pod:
+      x-kubernetes-group-version-kind:
+        - group: ""
+          kind: Pod
+          version: v1
+`,
+			format: kubeversion.FormatAuto,
+			want: kubeversion.Document{
+				Resources: []model.GroupVersionKind{
+					{Version: "v1", Kind: "Pod"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := kubeversion.Parse([]byte(tt.content), tt.format)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_Merge(t *testing.T) {
+	dataset := []model.KubernetesAPIVersion{
+		{KubeVersion: "1.9", APIVersions: []string{"extensions/v1beta1", "v1"}},
+		{KubeVersion: "1.10", APIVersions: []string{"v1"}},
+	}
+	resources := []model.GroupVersionKind{
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Version: "v1", Kind: "ConfigMap"},
+		{Version: "v1"},
+	}
+
+	tests := []struct {
+		name        string
+		kubeVersion string
+		replace     bool
+		want        []model.KubernetesAPIVersion
+	}{
+		{
+			name:        "should add a new Kubernetes version in order",
+			kubeVersion: "1.11",
+			want: []model.KubernetesAPIVersion{
+				{KubeVersion: "1.9", APIVersions: []string{"extensions/v1beta1", "v1"}},
+				{KubeVersion: "1.10", APIVersions: []string{"v1"}},
+				{
+					KubeVersion: "1.11",
+					APIVersions: []string{"apps/v1", "v1"},
+					Resources: []model.GroupVersionKind{
+						{Group: "apps", Version: "v1", Kind: "Deployment"},
+						{Version: "v1", Kind: "ConfigMap"},
+					},
+				},
+			},
+		},
+		{
+			name:        "should merge into an existing Kubernetes version",
+			kubeVersion: "1.9",
+			want: []model.KubernetesAPIVersion{
+				{
+					KubeVersion: "1.9",
+					APIVersions: []string{"apps/v1", "extensions/v1beta1", "v1"},
+					Resources: []model.GroupVersionKind{
+						{Group: "apps", Version: "v1", Kind: "Deployment"},
+						{Version: "v1", Kind: "ConfigMap"},
+					},
+				},
+				{KubeVersion: "1.10", APIVersions: []string{"v1"}},
+			},
+		},
+		{
+			name:        "should replace an existing Kubernetes version",
+			kubeVersion: "1.9",
+			replace:     true,
+			want: []model.KubernetesAPIVersion{
+				{
+					KubeVersion: "1.9",
+					APIVersions: []string{"apps/v1", "v1"},
+					Resources: []model.GroupVersionKind{
+						{Group: "apps", Version: "v1", Kind: "Deployment"},
+						{Version: "v1", Kind: "ConfigMap"},
+					},
+				},
+				{KubeVersion: "1.10", APIVersions: []string{"v1"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := append([]model.KubernetesAPIVersion{}, dataset...)
+			actual := kubeversion.Merge(current, tt.kubeVersion, resources, tt.replace)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
@@ -1,8 +1,24 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownKubeVersion = errors.New("unknown kubernetes version")
+	ErrInvalidPolicy      = errors.New("invalid policy")
+	ErrPolicyNotFound     = errors.New("policy not found")
+	ErrChartNotFound      = errors.New("chart not found")
+	ErrInvalidVersion     = errors.New("invalid chart version")
+	ErrInvalidSnapshot    = errors.New("invalid snapshot")
+	ErrSnapshotNotFound   = errors.New("snapshot not found")
+	ErrRenderNotFound     = errors.New("render not found")
+	ErrInvalidPostRender  = errors.New("invalid post render")
+	ErrInvalidBatch       = errors.New("invalid batch")
+	ErrInvalidShowOnly    = errors.New("invalid show_only")
 )
 
 type Repo struct {
@@ -44,33 +60,348 @@ type RepoDetailResponse struct {
 }
 
 type ChartResponse struct {
-	Name    string   `yaml:"name"`
-	Version string   `yaml:"version"`
-	URLs    []string `yaml:"urls"`
+	Name        string            `yaml:"name"`
+	Version     string            `yaml:"version"`
+	AppVersion  string            `yaml:"appVersion"`
+	Created     string            `yaml:"created"`
+	Annotations map[string]string `yaml:"annotations"`
+	URLs        []string          `yaml:"urls"`
+}
+
+type Changelog struct {
+	Repo     string           `json:"repo"`
+	Chart    string           `json:"chart"`
+	From     string           `json:"from,omitempty"`
+	To       string           `json:"to,omitempty"`
+	Versions []ChangelogEntry `json:"versions"`
+}
+
+type ChangelogEntry struct {
+	Version    string        `json:"version"`
+	AppVersion string        `json:"app_version,omitempty"`
+	Created    string        `json:"created,omitempty"`
+	Changes    []ChartChange `json:"changes,omitempty"`
+}
+
+type ChartChange struct {
+	Kind        string       `json:"kind,omitempty" yaml:"kind"`
+	Description string       `json:"description" yaml:"description"`
+	Links       []ChangeLink `json:"links,omitempty" yaml:"links"`
+}
+
+type ChangeLink struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
 }
 
 type Manifest struct {
-	Name    string `json:"name"`
-	Content string `json:"content"`
+	Name     string        `json:"name"`
+	Template string        `json:"template,omitempty"`
+	Content  string        `json:"content"`
+	Hook     *ManifestHook `json:"hook,omitempty"`
+}
+
+type ManifestHook struct {
+	Events         []string `json:"events"`
+	Weight         int      `json:"weight"`
+	DeletePolicies []string `json:"delete_policies,omitempty"`
 }
 
 type ManifestResponse struct {
 	URL       string     `json:"url"`
 	Manifests []Manifest `json:"manifests"`
+	CRDs      []Manifest `json:"crds,omitempty"`
+	Notes     string     `json:"notes,omitempty"`
+}
+
+type RenderResult struct {
+	Manifests []Manifest
+	CRDs      []Manifest
+	Notes     string
+	Values    map[string]interface{}
 }
 
 type KubernetesAPIVersion struct {
-	KubeVersion string   `json:"kube_version"`
-	APIVersions []string `json:"api_versions"`
+	KubeVersion string             `json:"kube_version"`
+	APIVersions []string           `json:"api_versions"`
+	Resources   []GroupVersionKind `json:"resources,omitempty"`
+}
+
+type CompatibilityMatrix struct {
+	Repo         string               `json:"repo"`
+	Chart        string               `json:"chart"`
+	KubeVersions []string             `json:"kube_versions"`
+	Versions     []ChartCompatibility `json:"versions"`
+}
+
+type ChartCompatibility struct {
+	Version             string   `json:"version"`
+	MinKubeVersion      string   `json:"min_kube_version,omitempty"`
+	MaxKubeVersion      string   `json:"max_kube_version,omitempty"`
+	KubeVersions        []string `json:"kube_versions"`
+	UnknownKubeVersions []string `json:"unknown_kube_versions"`
+	Error               string   `json:"error,omitempty"`
+}
+
+type ManifestValidationResult struct {
+	Name       string            `json:"name"`
+	Template   string            `json:"template,omitempty"`
+	APIVersion string            `json:"api_version,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Resource   string            `json:"resource,omitempty"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	Errors     []ValidationError `json:"errors,omitempty"`
+}
+
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type RuleFinding struct {
+	RuleID    string `json:"rule_id"`
+	Severity  string `json:"severity"`
+	Manifest  string `json:"manifest"`
+	Template  string `json:"template,omitempty"`
+	Kind      string `json:"kind"`
+	Resource  string `json:"resource"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+}
+
+type Policy struct {
+	ID          string   `json:"id"`
+	Severity    string   `json:"severity"`
+	Description string   `json:"description,omitempty"`
+	Kinds       []string `json:"kinds,omitempty"`
+	Expression  string   `json:"expression"`
+	Message     string   `json:"message,omitempty"`
+}
+
+type ChartReport struct {
+	Repo     string        `json:"repo"`
+	Chart    string        `json:"chart"`
+	Version  string        `json:"version"`
+	Summary  ReportSummary `json:"summary"`
+	Findings []RuleFinding `json:"findings"`
+}
+
+type ReportSummary struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+	ByRule     map[string]int `json:"by_rule"`
+}
+
+type ContainerImage struct {
+	Image      string           `json:"image"`
+	Registry   string           `json:"registry,omitempty"`
+	Repository string           `json:"repository,omitempty"`
+	Tag        string           `json:"tag,omitempty"`
+	Digest     string           `json:"digest,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	References []ImageReference `json:"references,omitempty"`
+}
+
+type ImageReference struct {
+	Manifest  string `json:"manifest"`
+	Template  string `json:"template,omitempty"`
+	Kind      string `json:"kind"`
+	Resource  string `json:"resource"`
+	Container string `json:"container"`
+	Init      bool   `json:"init,omitempty"`
+}
+
+type ImageInventory struct {
+	Repo    string           `json:"repo"`
+	Chart   string           `json:"chart"`
+	Version string           `json:"version"`
+	Images  []ContainerImage `json:"images"`
+}
+
+type ImageComparison struct {
+	Repo      string           `json:"repo"`
+	Chart     string           `json:"chart"`
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Added     []ContainerImage `json:"added"`
+	Removed   []ContainerImage `json:"removed"`
+	Changed   []ImageChange    `json:"changed"`
+	Unchanged []ContainerImage `json:"unchanged"`
+}
+
+type ImageChange struct {
+	Registry   string         `json:"registry"`
+	Repository string         `json:"repository"`
+	From       ContainerImage `json:"from"`
+	To         ContainerImage `json:"to"`
+}
+
+type ValuesDiff struct {
+	Repo    string        `json:"repo"`
+	Chart   string        `json:"chart"`
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Added   []ValueChange `json:"added"`
+	Removed []ValueChange `json:"removed"`
+	Changed []ValueChange `json:"changed"`
+}
+
+type ValueChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+type RenderSpec struct {
+	Version string        `json:"version"`
+	Values  string        `json:"values"`
+	Options RenderOptions `json:"options"`
+}
+
+type ManifestDiffRequest struct {
+	From RenderSpec `json:"from"`
+	To   RenderSpec `json:"to"`
+}
+
+type ManifestDiff struct {
+	Repo      string       `json:"repo"`
+	Chart     string       `json:"chart"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Added     []ObjectDiff `json:"added"`
+	Removed   []ObjectDiff `json:"removed"`
+	Changed   []ObjectDiff `json:"changed"`
+	Unchanged int          `json:"unchanged"`
+	Warnings  []string     `json:"warnings,omitempty"`
+}
+
+type ObjectIdentity struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (o ObjectIdentity) String() string {
+	if o.Namespace == "" {
+		return o.Kind + "/" + o.Name
+	}
+
+	return o.Kind + "/" + o.Namespace + "/" + o.Name
+}
+
+type ObjectDiff struct {
+	ObjectIdentity
+	Manifest string        `json:"manifest"`
+	Added    []ValueChange `json:"added,omitempty"`
+	Removed  []ValueChange `json:"removed,omitempty"`
+	Changed  []ValueChange `json:"changed,omitempty"`
+	Diff     string        `json:"diff"`
+}
+
+type TemplatesDiff struct {
+	Repo      string           `json:"repo"`
+	Chart     string           `json:"chart"`
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Added     []TemplateChange `json:"added"`
+	Removed   []TemplateChange `json:"removed"`
+	Modified  []TemplateChange `json:"modified"`
+	Renamed   []TemplateChange `json:"renamed"`
+	Unchanged int              `json:"unchanged"`
+}
+
+type TemplateChange struct {
+	Name                string   `json:"name"`
+	OldName             string   `json:"old_name,omitempty"`
+	Similarity          int      `json:"similarity,omitempty"`
+	AddedDefinitions    []string `json:"added_definitions,omitempty"`
+	RemovedDefinitions  []string `json:"removed_definitions,omitempty"`
+	ModifiedDefinitions []string `json:"modified_definitions,omitempty"`
+	Diff                string   `json:"diff"`
+}
+
+type UpgradeImpact struct {
+	Repo             string           `json:"repo"`
+	Chart            string           `json:"chart"`
+	From             string           `json:"from"`
+	To               string           `json:"to"`
+	Values           []ValueImpact    `json:"values"`
+	Recreated        []RecreateImpact `json:"recreated"`
+	RemovedResources []ObjectIdentity `json:"removed_resources"`
+	ImageChanges     []ImageChange    `json:"image_changes"`
+	AddedImages      []ContainerImage `json:"added_images"`
+	Warnings         []string         `json:"warnings,omitempty"`
+}
+
+type ValueImpact struct {
+	Path      string      `json:"path"`
+	Value     interface{} `json:"value"`
+	RenamedTo string      `json:"renamed_to,omitempty"`
+}
+
+type RecreateImpact struct {
+	ObjectIdentity
+	Manifest string   `json:"manifest"`
+	Fields   []string `json:"fields"`
+}
+
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+func (g GroupVersionKind) APIVersion() string {
+	if g.Group == "" {
+		return g.Version
+	}
+
+	return g.Group + "/" + g.Version
+}
+
+type APIDeprecation struct {
+	GroupVersionKind
+	DeprecatedIn string            `json:"deprecated_in,omitempty"`
+	RemovedIn    string            `json:"removed_in,omitempty"`
+	Replacement  *GroupVersionKind `json:"replacement,omitempty"`
 }
 
 type KubeResourceCommonSpec struct {
 	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
 }
 
 type AnalyticsResult struct {
 	Template
-	Compatible bool `json:"compatible"`
+	Compatible bool                `json:"compatible"`
+	Unchecked  bool                `json:"unchecked,omitempty"`
+	Resources  []ResourceAnalytics `json:"resources,omitempty"`
+	Warnings   []string            `json:"warnings,omitempty"`
+}
+
+type ResourceAnalytics struct {
+	APIVersion  string `json:"api_version"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Compatible  bool   `json:"compatible"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Removed     bool   `json:"removed,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+type ManifestAnalyticsResult struct {
+	Manifest
+	Compatible bool                `json:"compatible"`
+	Resources  []ResourceAnalytics `json:"resources,omitempty"`
+	Warnings   []string            `json:"warnings,omitempty"`
 }
 
 type AnalyticResponse struct {
@@ -79,5 +410,131 @@ type AnalyticResponse struct {
 }
 
 type RenderRequest struct {
-	Values string `json:"values"`
+	Values  string        `json:"values"`
+	Options RenderOptions `json:"options"`
+}
+
+type Snapshot struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Author    string        `json:"author,omitempty"`
+	Repo      string        `json:"repo"`
+	Chart     string        `json:"chart"`
+	Version   string        `json:"version"`
+	Values    string        `json:"values"`
+	Options   RenderOptions `json:"options"`
+	ParentID  string        `json:"parent_id,omitempty"`
+	URL       string        `json:"url"`
+	CreatedAt time.Time     `json:"created_at"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	Manifests []Manifest    `json:"manifests,omitempty"`
+	CRDs      []Manifest    `json:"crds,omitempty"`
+	Notes     string        `json:"notes,omitempty"`
+}
+
+type SnapshotRequest struct {
+	Name      string        `json:"name"`
+	Author    string        `json:"author"`
+	Values    string        `json:"values"`
+	Options   RenderOptions `json:"options"`
+	ExpiresIn string        `json:"expires_in"`
+}
+
+type SnapshotRerenderRequest struct {
+	Version   string `json:"version"`
+	Name      string `json:"name"`
+	Author    string `json:"author"`
+	ExpiresIn string `json:"expires_in"`
+}
+
+type BatchRenderRequest struct {
+	Releases []BatchRelease `json:"releases"`
+}
+
+type BatchRelease struct {
+	Name    string        `json:"name"`
+	Repo    string        `json:"repo"`
+	Chart   string        `json:"chart"`
+	Version string        `json:"version"`
+	Values  string        `json:"values"`
+	Options RenderOptions `json:"options"`
+}
+
+type BatchRender struct {
+	Succeeded  int                  `json:"succeeded"`
+	Failed     int                  `json:"failed"`
+	Releases   []BatchReleaseResult `json:"releases"`
+	Manifests  []ReleaseManifest    `json:"manifests"`
+	Duplicates []DuplicateObject    `json:"duplicates"`
+}
+
+type BatchReleaseResult struct {
+	Name        string             `json:"name"`
+	Repo        string             `json:"repo"`
+	Chart       string             `json:"chart"`
+	Version     string             `json:"version"`
+	URL         string             `json:"url,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	Diagnostics []RenderDiagnostic `json:"diagnostics,omitempty"`
+}
+
+type ReleaseManifest struct {
+	Release string `json:"release"`
+	Manifest
+}
+
+type DuplicateObject struct {
+	ObjectIdentity
+	Occurrences []ObjectOccurrence `json:"occurrences"`
+}
+
+type ObjectOccurrence struct {
+	Release    string `json:"release"`
+	Manifest   string `json:"manifest"`
+	APIVersion string `json:"api_version"`
+}
+
+type RenderOptions struct {
+	SkipHooks    bool        `json:"skip_hooks,omitempty"`
+	IncludeTests bool        `json:"include_tests,omitempty"`
+	ShowOnly     []string    `json:"show_only,omitempty"`
+	PostRender   *PostRender `json:"post_render,omitempty"`
+}
+
+type PostRender struct {
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Patches     []Patch           `json:"patches,omitempty"`
+}
+
+type Patch struct {
+	Type   string       `json:"type"`
+	Target *PatchTarget `json:"target,omitempty"`
+	Patch  string       `json:"patch"`
+}
+
+type PatchTarget struct {
+	APIVersion string `json:"api_version,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+type RenderDiagnostic struct {
+	Phase    string `json:"phase"`
+	Template string `json:"template"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	Snippet  string `json:"snippet,omitempty"`
+}
+
+type RenderError struct {
+	Message     string             `json:"error"`
+	Diagnostics []RenderDiagnostic `json:"diagnostics"`
+}
+
+func (e *RenderError) Error() string {
+	return e.Message
 }
@@ -0,0 +1,200 @@
+package output
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"chart-viewer/pkg/model"
+	"gopkg.in/yaml.v3"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	FormatJSON      = "json"
+	FormatYAML      = "yaml"
+	FormatList      = "list"
+	FormatTar       = "tar"
+	FormatKustomize = "kustomize"
+)
+
+const kustomizationFile = "kustomization.yaml"
+
+// archiveTime is the modification time of every archived file, so the same
+// manifests always give the same archive.
+var archiveTime = time.Unix(0, 0).UTC()
+
+// IsArchive tells whether the format is written as a tar.gz archive.
+func IsArchive(format string) bool {
+	return format == FormatTar || format == FormatKustomize
+}
+
+// ContentType returns the media type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatJSON, FormatList:
+		return "application/json"
+	case FormatTar, FormatKustomize:
+		return "application/gzip"
+	default:
+		return "text/plain"
+	}
+}
+
+// Write writes the manifests in the given format. JSON is the manifest
+// array, YAML a stream of documents, List a Kubernetes v1 List, Tar an archive
+// with one file per template path and Kustomize the same archive with a
+// kustomization.yaml listing every file as a resource.
+func Write(w io.Writer, format string, manifests []model.Manifest) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(manifests)
+	case FormatYAML:
+		_, err := io.WriteString(w, stream(manifests))
+		return err
+	case FormatList:
+		return writeList(w, manifests)
+	case FormatTar:
+		return writeArchive(w, files(manifests), false)
+	case FormatKustomize:
+		return writeArchive(w, files(manifests), true)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func stream(manifests []model.Manifest) string {
+	var buffer bytes.Buffer
+	for _, m := range manifests {
+		buffer.WriteString("---\n" + m.Content + "\n")
+	}
+
+	return buffer.String()
+}
+
+func writeList(w io.Writer, manifests []model.Manifest) error {
+	items := []json.RawMessage{}
+	for _, m := range manifests {
+		item, err := k8syaml.YAMLToJSON([]byte(m.Content))
+		if err != nil {
+			return fmt.Errorf("cannot convert %s to JSON: %w", m.Name, err)
+		}
+
+		if string(item) == "null" {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Items      []json.RawMessage `json:"items"`
+	}{
+		APIVersion: "v1",
+		Kind:       "List",
+		Items:      items,
+	})
+}
+
+type file struct {
+	path    string
+	content strings.Builder
+}
+
+// files groups the manifests by template path, in the order the paths first
+// appear, since a template can render several documents.
+func files(manifests []model.Manifest) []*file {
+	var result []*file
+	byPath := map[string]*file{}
+	for _, m := range manifests {
+		filePath := archivePath(m)
+		f, ok := byPath[filePath]
+		if !ok {
+			f = &file{path: filePath}
+			byPath[filePath] = f
+			result = append(result, f)
+		} else {
+			f.content.WriteString("---\n")
+		}
+
+		f.content.WriteString(strings.TrimRight(m.Content, "\n") + "\n")
+	}
+
+	return result
+}
+
+// archivePath returns the template path of the manifest, kept inside the
+// archive even if the path tries to leave it.
+func archivePath(m model.Manifest) string {
+	name := m.Template
+	if name == "" {
+		name = m.Name
+	}
+
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func writeArchive(w io.Writer, files []*file, kustomize bool) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	var resources []string
+	for _, f := range files {
+		err := writeFile(tarWriter, f.path, f.content.String())
+		if err != nil {
+			return err
+		}
+		resources = append(resources, f.path)
+	}
+
+	if kustomize {
+		kustomization, err := yaml.Marshal(struct {
+			APIVersion string   `yaml:"apiVersion"`
+			Kind       string   `yaml:"kind"`
+			Resources  []string `yaml:"resources"`
+		}{
+			APIVersion: "kustomize.config.k8s.io/v1beta1",
+			Kind:       "Kustomization",
+			Resources:  resources,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(tarWriter, kustomizationFile, string(kustomization))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+func writeFile(tarWriter *tar.Writer, name, content string) error {
+	err := tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: archiveTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(tarWriter, content)
+	return err
+}
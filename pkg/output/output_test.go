@@ -0,0 +1,130 @@
+package output_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/output"
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/assert"
+)
+
+var manifests = []model.Manifest{
+	{
+		Name:     "crd.yaml",
+		Template: "crds/crd.yaml",
+		Content:  "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: backups.acme.io\n",
+	},
+	{
+		Name:     "service.yaml",
+		Template: "templates/service.yaml",
+		Content:  "# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  ports:\n    - port: 80",
+	},
+	{
+		Name:     "service.yaml",
+		Template: "templates/service.yaml",
+		Content:  "# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app-headless",
+	},
+	{
+		Name:     "empty.yaml",
+		Template: "templates/empty.yaml",
+		Content:  "# Source: app/templates/empty.yaml",
+	},
+}
+
+func Test_Write_yaml(t *testing.T) {
+	var actual bytes.Buffer
+	err := output.Write(&actual, output.FormatYAML, manifests[1:3])
+	assert.NoError(t, err)
+	assert.Equal(t, "---\n"+manifests[1].Content+"\n---\n"+manifests[2].Content+"\n", actual.String())
+}
+
+func Test_Write_list(t *testing.T) {
+	var actual bytes.Buffer
+	err := output.Write(&actual, output.FormatList, manifests)
+	assert.NoError(t, err)
+
+	ja := jsonassert.New(t)
+	ja.Assertf(actual.String(), `{
+		"apiVersion": "v1",
+		"kind": "List",
+		"items": [
+			{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "backups.acme.io"}},
+			{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "app"}, "spec": {"ports": [{"port": 80}]}},
+			{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "app-headless"}}
+		]
+	}`)
+}
+
+func Test_Write_archives(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   map[string]string
+	}{
+		{
+			name:   "should write one file per template path",
+			format: output.FormatTar,
+			want: map[string]string{
+				"crds/crd.yaml":          manifests[0].Content,
+				"templates/service.yaml": manifests[1].Content + "\n---\n" + manifests[2].Content + "\n",
+				"templates/empty.yaml":   manifests[3].Content + "\n",
+			},
+		},
+		{
+			name:   "should add a kustomization listing the files",
+			format: output.FormatKustomize,
+			want: map[string]string{
+				"crds/crd.yaml":          manifests[0].Content,
+				"templates/service.yaml": manifests[1].Content + "\n---\n" + manifests[2].Content + "\n",
+				"templates/empty.yaml":   manifests[3].Content + "\n",
+				"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\n" +
+					"kind: Kustomization\n" +
+					"resources:\n" +
+					"    - crds/crd.yaml\n" +
+					"    - templates/service.yaml\n" +
+					"    - templates/empty.yaml\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var archive bytes.Buffer
+			err := output.Write(&archive, tt.format, manifests)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, readArchive(t, archive.Bytes()))
+		})
+	}
+}
+
+func Test_Write_archivePath(t *testing.T) {
+	var archive bytes.Buffer
+	err := output.Write(&archive, output.FormatTar, []model.Manifest{{Name: "escape.yaml", Template: "../../escape.yaml", Content: "kind: Secret"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"escape.yaml": "kind: Secret\n"}, readArchive(t, archive.Bytes()))
+}
+
+func readArchive(t *testing.T, archive []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+
+	return files
+}
@@ -0,0 +1,322 @@
+package postrender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"chart-viewer/pkg/model"
+	jsonpatch "github.com/evanphx/json-patch"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	PatchStrategicMerge = "strategic-merge"
+	PatchJSON6902       = "json6902"
+)
+
+// clusterScoped are the built-in kinds without a namespace. Every other kind,
+// custom resources included, is treated as namespaced.
+var clusterScoped = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CustomResourceDefinition":       true,
+	"FlowSchema":                     true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"PriorityLevelConfiguration":     true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+type patch struct {
+	index      int
+	patchType  string
+	target     model.PatchTarget
+	document   []byte
+	operations jsonpatch.Patch
+	matched    bool
+}
+
+type PostRenderer struct {
+	options model.PostRender
+	patches []*patch
+}
+
+// New checks the patches of the pipeline and returns a helm post-renderer
+// running it: the patches first, matched against the objects as the chart
+// renders them, then the namespace, labels and annotations.
+func New(options model.PostRender) (*PostRenderer, error) {
+	p := &PostRenderer{options: options}
+	for i, op := range options.Patches {
+		parsed, err := parsePatch(i, op)
+		if err != nil {
+			return nil, fmt.Errorf("%w: patch %d: %s", model.ErrInvalidPostRender, i, err)
+		}
+		p.patches = append(p.patches, parsed)
+	}
+
+	return p, nil
+}
+
+// Run implements the helm postrender.PostRenderer interface, transforming
+// every document of the rendered stream.
+func (p *PostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	documents := releaseutil.SplitManifests(renderedManifests.String())
+	keys := make([]string, 0, len(documents))
+	for k := range documents {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	result := new(bytes.Buffer)
+	for _, k := range keys {
+		document, err := p.Transform(documents[k])
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(result, "---\n%s\n", document)
+	}
+
+	return result, nil
+}
+
+// Transform runs the pipeline on a single document, keeping its leading
+// comments such as the # Source line.
+func (p *PostRenderer) Transform(document string) (string, error) {
+	content, err := k8syaml.YAMLToJSON([]byte(document))
+	if err != nil {
+		return "", err
+	}
+	if string(content) == "null" {
+		return document, nil
+	}
+
+	object, err := decode(content)
+	if err != nil {
+		return "", err
+	}
+
+	for _, patch := range p.patches {
+		if !patch.matches(object) {
+			continue
+		}
+
+		content, err = patch.apply(content, object)
+		if err != nil {
+			return "", fmt.Errorf("%w: patch %d on %s: %s", model.ErrInvalidPostRender, patch.index, identity(object), err)
+		}
+		patch.matched = true
+
+		object, err = decode(content)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if p.options.Namespace != "" && !clusterScoped[stringField(object, "kind")] {
+		metadata(object)["namespace"] = p.options.Namespace
+	}
+	setAll(metadata(object), "labels", p.options.Labels)
+	setAll(metadata(object), "annotations", p.options.Annotations)
+
+	content, err = json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+
+	transformed, err := k8syaml.JSONToYAML(content)
+	if err != nil {
+		return "", err
+	}
+
+	return header(document) + strings.TrimSpace(string(transformed)), nil
+}
+
+// CheckMatched returns an error naming the patches that matched none of the
+// transformed documents, which usually means a wrong target.
+func (p *PostRenderer) CheckMatched() error {
+	var unmatched []string
+	for _, patch := range p.patches {
+		if !patch.matched {
+			unmatched = append(unmatched, fmt.Sprint(patch.index))
+		}
+	}
+
+	if len(unmatched) != 0 {
+		return fmt.Errorf("%w: patches %s match no object", model.ErrInvalidPostRender, strings.Join(unmatched, ", "))
+	}
+
+	return nil
+}
+
+func parsePatch(index int, op model.Patch) (*patch, error) {
+	result := &patch{index: index, patchType: op.Type}
+	if result.patchType == "" {
+		result.patchType = PatchStrategicMerge
+	}
+
+	document, err := k8syaml.YAMLToJSON([]byte(op.Patch))
+	if err != nil {
+		return nil, err
+	}
+	result.document = document
+
+	switch result.patchType {
+	case PatchStrategicMerge:
+		object, err := decode(document)
+		if err != nil {
+			return nil, fmt.Errorf("a strategic merge patch must be an object")
+		}
+
+		// Like kustomize, a strategic merge patch without a target patches
+		// the object it names.
+		if op.Target == nil {
+			result.target = model.PatchTarget{
+				APIVersion: stringField(object, "apiVersion"),
+				Kind:       stringField(object, "kind"),
+				Namespace:  stringField(object, "metadata", "namespace"),
+				Name:       stringField(object, "metadata", "name"),
+			}
+			if result.target.Kind == "" || result.target.Name == "" {
+				return nil, fmt.Errorf("needs a target or the kind and name of the object to patch")
+			}
+		}
+	case PatchJSON6902:
+		result.operations, err = jsonpatch.DecodePatch(document)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Target == nil {
+			return nil, fmt.Errorf("a json6902 patch needs a target")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q, use %s or %s", op.Type, PatchStrategicMerge, PatchJSON6902)
+	}
+
+	if op.Target != nil {
+		result.target = *op.Target
+	}
+
+	return result, nil
+}
+
+func (p *patch) matches(object map[string]interface{}) bool {
+	return matchField(p.target.APIVersion, stringField(object, "apiVersion")) &&
+		matchField(p.target.Kind, stringField(object, "kind")) &&
+		matchField(p.target.Namespace, stringField(object, "metadata", "namespace")) &&
+		matchField(p.target.Name, stringField(object, "metadata", "name"))
+}
+
+// apply patches the document. Strategic merge patches need the schema of the
+// kind to know how to merge lists, so kinds unknown to the client, such as
+// custom resources, get a JSON merge patch like kubectl does.
+func (p *patch) apply(document []byte, object map[string]interface{}) ([]byte, error) {
+	if p.patchType == PatchJSON6902 {
+		return p.operations.Apply(document)
+	}
+
+	gvk := schema.FromAPIVersionAndKind(stringField(object, "apiVersion"), stringField(object, "kind"))
+	dataStruct, err := scheme.Scheme.New(gvk)
+	if err != nil {
+		return jsonpatch.MergePatch(document, p.document)
+	}
+
+	return strategicpatch.StrategicMergePatch(document, p.document, dataStruct)
+}
+
+// decode keeps numbers as they are written, so large integers are not turned
+// into floats when the object is written back.
+func decode(content []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	err := decoder.Decode(&object)
+	if err != nil {
+		return nil, err
+	}
+	if object == nil {
+		return nil, fmt.Errorf("not an object")
+	}
+
+	return object, nil
+}
+
+func matchField(want, value string) bool {
+	return want == "" || want == value
+}
+
+func stringField(object map[string]interface{}, path ...string) string {
+	var current interface{} = object
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[key]
+	}
+
+	value, _ := current.(string)
+	return value
+}
+
+func metadata(object map[string]interface{}) map[string]interface{} {
+	m, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+		object["metadata"] = m
+	}
+
+	return m
+}
+
+func setAll(object map[string]interface{}, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	m, ok := object[key].(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+		object[key] = m
+	}
+
+	for k, v := range values {
+		m[k] = v
+	}
+}
+
+func identity(object map[string]interface{}) string {
+	return fmt.Sprintf("%s/%s", stringField(object, "kind"), stringField(object, "metadata", "name"))
+}
+
+func header(document string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimLeft(document, "\n"), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,250 @@
+package postrender_test
+
+import (
+	"bytes"
+	"testing"
+
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/postrender"
+	"github.com/stretchr/testify/assert"
+)
+
+const deployment = `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: app
+spec:
+  replicas: 1
+  progressDeadlineSeconds: 1000000
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.0.0
+        - name: sidecar
+          image: sidecar:1.0.0`
+
+func Test_PostRenderer_Transform(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  model.PostRender
+		document string
+		want     string
+		wantErr  error
+	}{
+		{
+			name: "should set the namespace, labels and annotations and keep the source comment",
+			options: model.PostRender{
+				Namespace:   "team-a",
+				Labels:      map[string]string{"team": "a", "app": "web"},
+				Annotations: map[string]string{"owner": "team-a@example.com"},
+			},
+			document: deployment,
+			want: `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    owner: team-a@example.com
+  labels:
+    app: web
+    team: a
+  name: app
+  namespace: team-a
+spec:
+  progressDeadlineSeconds: 1000000
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: app:1.0.0
+        name: app
+      - image: sidecar:1.0.0
+        name: sidecar`,
+		},
+		{
+			name:     "should not set the namespace of cluster scoped objects",
+			options:  model.PostRender{Namespace: "team-a"},
+			document: "# Source: app/templates/clusterrole.yaml\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: app",
+			want:     "# Source: app/templates/clusterrole.yaml\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: app",
+		},
+		{
+			name: "should merge containers by name with a strategic merge patch naming the object",
+			options: model.PostRender{
+				Patches: []model.Patch{
+					{Patch: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  template:\n    spec:\n      containers:\n        - name: sidecar\n          image: sidecar:2.0.0"},
+				},
+			},
+			document: deployment,
+			want: `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: app
+  name: app
+spec:
+  progressDeadlineSeconds: 1000000
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - image: app:1.0.0
+        name: app
+      - image: sidecar:2.0.0
+        name: sidecar`,
+		},
+		{
+			name: "should apply a json6902 patch to the target",
+			options: model.PostRender{
+				Patches: []model.Patch{
+					{
+						Type:   postrender.PatchJSON6902,
+						Target: &model.PatchTarget{Kind: "Deployment", Name: "app"},
+						Patch:  "- op: replace\n  path: /spec/replicas\n  value: 3\n- op: remove\n  path: /spec/template/spec/containers/1",
+					},
+				},
+			},
+			document: deployment,
+			want: `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: app
+  name: app
+spec:
+  progressDeadlineSeconds: 1000000
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: app:1.0.0
+        name: app`,
+		},
+		{
+			name: "should merge patch custom resources",
+			options: model.PostRender{
+				Patches: []model.Patch{
+					{
+						Target: &model.PatchTarget{Kind: "Backup"},
+						Patch:  "spec:\n  schedule: 0 1 * * *\n  paused: null",
+					},
+				},
+			},
+			document: "# Source: app/templates/backup.yaml\napiVersion: acme.io/v1\nkind: Backup\nmetadata:\n  name: app\nspec:\n  schedule: 0 0 * * *\n  paused: true",
+			want:     "# Source: app/templates/backup.yaml\napiVersion: acme.io/v1\nkind: Backup\nmetadata:\n  name: app\nspec:\n  schedule: 0 1 * * *",
+		},
+		{
+			name: "should leave the objects the patch does not target",
+			options: model.PostRender{
+				Patches: []model.Patch{
+					{
+						Type:   postrender.PatchJSON6902,
+						Target: &model.PatchTarget{Kind: "Deployment", Name: "other"},
+						Patch:  "- op: replace\n  path: /spec/replicas\n  value: 3",
+					},
+				},
+			},
+			document: "# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app",
+			want:     "# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app",
+		},
+		{
+			name: "should return an error when a patch cannot be applied",
+			options: model.PostRender{
+				Patches: []model.Patch{
+					{
+						Type:   postrender.PatchJSON6902,
+						Target: &model.PatchTarget{Kind: "Deployment"},
+						Patch:  "- op: replace\n  path: /spec/strategy/type\n  value: Recreate",
+					},
+				},
+			},
+			document: deployment,
+			wantErr:  model.ErrInvalidPostRender,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := postrender.New(tt.options)
+			assert.NoError(t, err)
+
+			got, err := p.Transform(tt.document)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_PostRenderer_Run(t *testing.T) {
+	p, err := postrender.New(model.PostRender{Namespace: "team-a"})
+	assert.NoError(t, err)
+
+	rendered := bytes.NewBufferString("---\n# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\n" +
+		"---\n# Source: app/templates/empty.yaml\n" +
+		"---\n# Source: app/templates/configmap.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n")
+
+	got, err := p.Run(rendered)
+	assert.NoError(t, err)
+	assert.Equal(t, "---\n# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\n  namespace: team-a\n"+
+		"---\n# Source: app/templates/empty.yaml\n"+
+		"---\n# Source: app/templates/configmap.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: team-a\n", got.String())
+}
+
+func Test_New(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   model.Patch
+		wantErr string
+	}{
+		{
+			name:    "should reject unknown patch types",
+			patch:   model.Patch{Type: "merge", Patch: "spec: {}"},
+			wantErr: `invalid post render: patch 0: unsupported type "merge", use strategic-merge or json6902`,
+		},
+		{
+			name:    "should reject a strategic merge patch that names no object",
+			patch:   model.Patch{Patch: "spec:\n  replicas: 2"},
+			wantErr: "invalid post render: patch 0: needs a target or the kind and name of the object to patch",
+		},
+		{
+			name:    "should reject a json6902 patch without a target",
+			patch:   model.Patch{Type: postrender.PatchJSON6902, Patch: "- op: remove\n  path: /spec"},
+			wantErr: "invalid post render: patch 0: a json6902 patch needs a target",
+		},
+		{
+			name:    "should reject a json6902 patch that is not a list of operations",
+			patch:   model.Patch{Type: postrender.PatchJSON6902, Target: &model.PatchTarget{Kind: "Service"}, Patch: "op: remove"},
+			wantErr: "invalid post render: patch 0: json: cannot unmarshal object into Go value of type jsonpatch.Patch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := postrender.New(model.PostRender{Patches: []model.Patch{tt.patch}})
+			assert.EqualError(t, err, tt.wantErr)
+			assert.ErrorIs(t, err, model.ErrInvalidPostRender)
+		})
+	}
+}
+
+func Test_PostRenderer_CheckMatched(t *testing.T) {
+	p, err := postrender.New(model.PostRender{
+		Patches: []model.Patch{
+			{Target: &model.PatchTarget{Kind: "Service"}, Patch: "metadata:\n  labels:\n    exposed: \"true\""},
+			{Target: &model.PatchTarget{Kind: "Ingress"}, Patch: "metadata:\n  labels:\n    exposed: \"true\""},
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = p.Transform("apiVersion: v1\nkind: Service\nmetadata:\n  name: app")
+	assert.NoError(t, err)
+	assert.EqualError(t, p.CheckMatched(), "invalid post render: patches 1 match no object")
+}
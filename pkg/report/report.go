@@ -0,0 +1,391 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"chart-viewer/pkg/model"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+	FormatJUnit = "junit"
+)
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+const (
+	RuleRemovedAPI      = "removed-api"
+	RuleUnavailableAPI  = "unavailable-api"
+	RuleDeprecatedAPI   = "deprecated-api"
+	RuleAnalysisWarning = "analysis-warning"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "chart-viewer"
+	toolURI      = "https://github.com/ecojuntak/chart-viewer"
+)
+
+var ruleDescriptions = map[string]string{
+	RuleRemovedAPI:      "The API version is removed in the Kubernetes version",
+	RuleUnavailableAPI:  "The API version is not served by the Kubernetes version",
+	RuleDeprecatedAPI:   "The API version is deprecated in the Kubernetes version",
+	RuleAnalysisWarning: "The file cannot be fully analyzed",
+}
+
+// Check is the outcome of analyzing one file, either a chart template or the
+// template a rendered manifest comes from.
+type Check struct {
+	File   string
+	Issues []Issue
+}
+
+type Issue struct {
+	RuleID  string
+	Level   string
+	Message string
+	Line    int
+}
+
+func IsFormat(format string) bool {
+	return format == FormatJSON || format == FormatSARIF || format == FormatJUnit
+}
+
+// FromTemplates converts the analysis of chart templates. Template names are
+// joined to root, so the locations point to the chart files in a repository.
+func FromTemplates(root string, results []model.AnalyticsResult) []Check {
+	var checks []Check
+	for _, r := range results {
+		check := Check{File: path.Join(root, r.Name)}
+		for _, resource := range r.Resources {
+			if issue, ok := resourceIssue(resource); ok {
+				check.Issues = append(check.Issues, issue)
+			}
+		}
+		check.Issues = append(check.Issues, warningIssues(r.Warnings)...)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// FromManifests converts the analysis of rendered manifests, located at the
+// template they are rendered from.
+func FromManifests(results []model.ManifestAnalyticsResult) []Check {
+	var checks []Check
+	for _, r := range results {
+		check := Check{File: manifestFile(r.Manifest)}
+		for _, resource := range r.Resources {
+			if issue, ok := resourceIssue(resource); ok {
+				check.Issues = append(check.Issues, issue)
+			}
+		}
+		check.Issues = append(check.Issues, warningIssues(r.Warnings)...)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// FromFindings converts the rule and policy findings of a chart report, one
+// check for each manifest with findings.
+func FromFindings(findings []model.RuleFinding) []Check {
+	var checks []Check
+	index := map[string]int{}
+	for _, f := range findings {
+		file := manifestFile(model.Manifest{Name: f.Manifest, Template: f.Template})
+		i, ok := index[file]
+		if !ok {
+			i = len(checks)
+			index[file] = i
+			checks = append(checks, Check{File: file})
+		}
+
+		message := fmt.Sprintf("%s %s: %s", f.Kind, f.Resource, f.Message)
+		if f.Container != "" {
+			message = fmt.Sprintf("%s %s container %s: %s", f.Kind, f.Resource, f.Container, f.Message)
+		}
+
+		checks[i].Issues = append(checks[i].Issues, Issue{
+			RuleID:  f.RuleID,
+			Level:   severityLevel(f.Severity),
+			Message: message,
+		})
+	}
+
+	return checks
+}
+
+// Write encodes the checks as a SARIF log or a JUnit test suite with the given
+// name. Every check is a test case, which fails on error and warning issues.
+func Write(w io.Writer, format, name string, checks []Check) error {
+	switch format {
+	case FormatSARIF:
+		return writeSARIF(w, checks)
+	case FormatJUnit:
+		return writeJUnit(w, name, checks)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func resourceIssue(resource model.ResourceAnalytics) (Issue, bool) {
+	issue := Issue{Message: resource.Message, Line: resource.Line}
+	switch {
+	case resource.Removed:
+		issue.RuleID, issue.Level = RuleRemovedAPI, LevelError
+	case !resource.Compatible:
+		issue.RuleID, issue.Level = RuleUnavailableAPI, LevelError
+		issue.Message = fmt.Sprintf("%s %s is not served by the Kubernetes version", resource.APIVersion, resource.Kind)
+	case resource.Deprecated:
+		issue.RuleID, issue.Level = RuleDeprecatedAPI, LevelWarning
+	default:
+		return Issue{}, false
+	}
+
+	return issue, true
+}
+
+func warningIssues(warnings []string) []Issue {
+	var issues []Issue
+	for _, w := range warnings {
+		issues = append(issues, Issue{RuleID: RuleAnalysisWarning, Level: LevelNote, Message: w})
+	}
+
+	return issues
+}
+
+func manifestFile(manifest model.Manifest) string {
+	if manifest.Template != "" {
+		return manifest.Template
+	}
+
+	return manifest.Name
+}
+
+func severityLevel(severity string) string {
+	switch severity {
+	case "high":
+		return LevelError
+	case "medium":
+		return LevelWarning
+	default:
+		return LevelNote
+	}
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func writeSARIF(w io.Writer, checks []Check) error {
+	var ruleIDs []string
+	seen := map[string]bool{}
+	for _, c := range checks {
+		for _, issue := range c.Issues {
+			if !seen[issue.RuleID] {
+				seen[issue.RuleID] = true
+				ruleIDs = append(ruleIDs, issue.RuleID)
+			}
+		}
+	}
+	sort.Strings(ruleIDs)
+
+	rules := []sarifRule{}
+	ruleIndex := map[string]int{}
+	for i, id := range ruleIDs {
+		rule := sarifRule{ID: id}
+		if description, ok := ruleDescriptions[id]; ok {
+			rule.ShortDescription = &sarifMessage{Text: description}
+		}
+		rules = append(rules, rule)
+		ruleIndex[id] = i
+	}
+
+	results := []sarifResult{}
+	for _, c := range checks {
+		for _, issue := range c.Issues {
+			location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: c.File}}
+			if issue.Line != 0 {
+				location.Region = &sarifRegion{StartLine: issue.Line}
+			}
+
+			results = append(results, sarifResult{
+				RuleID:    issue.RuleID,
+				RuleIndex: ruleIndex[issue.RuleID],
+				Level:     issue.Level,
+				Message:   sarifMessage{Text: issue.Message},
+				Locations: []sarifLocation{{PhysicalLocation: location}},
+			})
+		}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{Name: toolName, InformationURI: toolURI, Rules: rules},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, name string, checks []Check) error {
+	suite := junitTestSuite{Name: name, TestCases: []junitTestCase{}}
+	for _, c := range checks {
+		testCase := junitTestCase{ClassName: name, Name: c.File}
+
+		var failures, notes []string
+		for _, issue := range c.Issues {
+			line := issueLine(c.File, issue)
+			if issue.Level == LevelNote {
+				notes = append(notes, line)
+				continue
+			}
+
+			if testCase.Failure == nil {
+				testCase.Failure = &junitFailure{Message: issue.Message, Type: issue.RuleID}
+			}
+			failures = append(failures, line)
+		}
+
+		if testCase.Failure != nil {
+			testCase.Failure.Content = strings.Join(failures, "\n")
+			suite.Failures++
+		}
+		testCase.SystemOut = strings.Join(notes, "\n")
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	suites := junitTestSuites{
+		Name:     name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(suites)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func issueLine(file string, issue Issue) string {
+	location := file
+	if issue.Line != 0 {
+		location = fmt.Sprintf("%s:%d", file, issue.Line)
+	}
+
+	return fmt.Sprintf("%s: [%s] %s", location, issue.RuleID, issue.Message)
+}
@@ -0,0 +1,142 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/report"
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/assert"
+)
+
+var templateResults = []model.AnalyticsResult{
+	{
+		Template:   model.Template{Name: "templates/app.yaml"},
+		Compatible: false,
+		Resources: []model.ResourceAnalytics{
+			{APIVersion: "apps/v1", Kind: "Deployment", Line: 1, Compatible: true},
+			{
+				APIVersion: "extensions/v1beta1",
+				Kind:       "Ingress",
+				Line:       6,
+				Compatible: false,
+				Deprecated: true,
+				Removed:    true,
+				Message:    "extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1",
+			},
+			{
+				APIVersion: "batch/v1beta1",
+				Kind:       "CronJob",
+				Line:       11,
+				Compatible: true,
+				Deprecated: true,
+				Message:    "batch/v1beta1 CronJob deprecated in 1.21 and removed in 1.25, use batch/v1",
+			},
+		},
+	},
+	{
+		Template:   model.Template{Name: "templates/service.yaml"},
+		Compatible: true,
+		Resources: []model.ResourceAnalytics{
+			{APIVersion: "v1", Kind: "Service", Line: 1, Compatible: true},
+		},
+		Warnings: []string{"document 2: apiVersion is set by a template action and can only be checked on rendered manifests"},
+	},
+}
+
+func Test_Write_sarif(t *testing.T) {
+	var output bytes.Buffer
+	err := report.Write(&output, report.FormatSARIF, "app", report.FromTemplates("charts/app", templateResults))
+	assert.NoError(t, err)
+
+	ja := jsonassert.New(t)
+	ja.Assertf(output.String(), `{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": [
+			{
+				"tool": {
+					"driver": {
+						"name": "chart-viewer",
+						"informationUri": "https://github.com/ecojuntak/chart-viewer",
+						"rules": [
+							{"id": "analysis-warning", "shortDescription": {"text": "The file cannot be fully analyzed"}},
+							{"id": "deprecated-api", "shortDescription": {"text": "The API version is deprecated in the Kubernetes version"}},
+							{"id": "removed-api", "shortDescription": {"text": "The API version is removed in the Kubernetes version"}}
+						]
+					}
+				},
+				"results": [
+					{
+						"ruleId": "removed-api",
+						"ruleIndex": 2,
+						"level": "error",
+						"message": {"text": "extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1"},
+						"locations": [{"physicalLocation": {"artifactLocation": {"uri": "charts/app/templates/app.yaml"}, "region": {"startLine": 6}}}]
+					},
+					{
+						"ruleId": "deprecated-api",
+						"ruleIndex": 1,
+						"level": "warning",
+						"message": {"text": "batch/v1beta1 CronJob deprecated in 1.21 and removed in 1.25, use batch/v1"},
+						"locations": [{"physicalLocation": {"artifactLocation": {"uri": "charts/app/templates/app.yaml"}, "region": {"startLine": 11}}}]
+					},
+					{
+						"ruleId": "analysis-warning",
+						"ruleIndex": 0,
+						"level": "note",
+						"message": {"text": "document 2: apiVersion is set by a template action and can only be checked on rendered manifests"},
+						"locations": [{"physicalLocation": {"artifactLocation": {"uri": "charts/app/templates/service.yaml"}}}]
+					}
+				]
+			}
+		]
+	}`)
+}
+
+func Test_Write_junit(t *testing.T) {
+	var output bytes.Buffer
+	err := report.Write(&output, report.FormatJUnit, "app", report.FromTemplates("", templateResults))
+	assert.NoError(t, err)
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="app" tests="2" failures="1">
+  <testsuite name="app" tests="2" failures="1">
+    <testcase classname="app" name="templates/app.yaml">
+      <failure message="extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1" type="removed-api">templates/app.yaml:6: [removed-api] extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1&#xA;templates/app.yaml:11: [deprecated-api] batch/v1beta1 CronJob deprecated in 1.21 and removed in 1.25, use batch/v1</failure>
+    </testcase>
+    <testcase classname="app" name="templates/service.yaml">
+      <system-out>templates/service.yaml: [analysis-warning] document 2: apiVersion is set by a template action and can only be checked on rendered manifests</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, want, output.String())
+}
+
+func Test_FromFindings(t *testing.T) {
+	findings := []model.RuleFinding{
+		{RuleID: "privileged-container", Severity: "high", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container runs in privileged mode"},
+		{RuleID: "latest-image-tag", Severity: "medium", Manifest: "deployment.yaml", Template: "templates/deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "image uses the latest tag"},
+		{RuleID: "no-load-balancer", Severity: "low", Manifest: "service.yaml", Template: "templates/service.yaml", Kind: "Service", Resource: "app", Message: "services must not be exposed through a load balancer"},
+	}
+
+	want := []report.Check{
+		{
+			File: "templates/deployment.yaml",
+			Issues: []report.Issue{
+				{RuleID: "privileged-container", Level: report.LevelError, Message: "Deployment app container app: container runs in privileged mode"},
+				{RuleID: "latest-image-tag", Level: report.LevelWarning, Message: "Deployment app container app: image uses the latest tag"},
+			},
+		},
+		{
+			File: "templates/service.yaml",
+			Issues: []report.Issue{
+				{RuleID: "no-load-balancer", Level: report.LevelNote, Message: "Service app: services must not be exposed through a load balancer"},
+			},
+		},
+	}
+
+	assert.Equal(t, want, report.FromFindings(findings))
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/go-redis/redis"
 )
 
@@ -17,11 +19,50 @@ func (r repository) Set(key string, value string) error {
 	return status.Err()
 }
 
+func (r repository) SetWithExpiration(key string, value string, expiration time.Duration) error {
+	status := r.redisClient.Set(key, value, expiration)
+	return status.Err()
+}
+
+// Get returns an empty string for a key that does not exist, which the
+// callers treat as a cache miss.
 func (r repository) Get(key string) (string, error) {
 	status := r.redisClient.Get(key)
+	if status.Err() == redis.Nil {
+		return "", nil
+	}
 	if status.Err() != nil {
 		return "", status.Err()
 	}
 
 	return status.Result()
 }
+
+func (r repository) Delete(key string) error {
+	status := r.redisClient.Del(key)
+	return status.Err()
+}
+
+func (r repository) Exists(key string) (bool, error) {
+	status := r.redisClient.Exists(key)
+	if status.Err() != nil {
+		return false, status.Err()
+	}
+
+	return status.Val() == 1, nil
+}
+
+func (r repository) HSet(key, field, value string) error {
+	status := r.redisClient.HSet(key, field, value)
+	return status.Err()
+}
+
+func (r repository) HGetAll(key string) (map[string]string, error) {
+	status := r.redisClient.HGetAll(key)
+	return status.Result()
+}
+
+func (r repository) HDel(key, field string) error {
+	status := r.redisClient.HDel(key, field)
+	return status.Err()
+}
@@ -1,24 +1,52 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 
+	"chart-viewer/pkg/diff"
 	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/output"
+	"chart-viewer/pkg/report"
 	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
 )
 
 type Service interface {
 	GetRepos() ([]model.Repo, error)
 	GetCharts(repoName string) ([]model.Chart, error)
+	GetChangelog(repoName, chartName, fromVersion, toVersion string) (model.Changelog, error)
 	GetValues(repoName, chartName, chartVersion string) (map[string]interface{}, error)
 	GetTemplates(repoName, chartName, chartVersion string) ([]model.Template, error)
-	RenderManifest(repoName, chartName, chartVersion string, values string) (model.ManifestResponse, error)
-	GetStringifiedManifests(repoName, chartName, chartVersion, hash string) (string, error)
+	RenderManifest(repoName, chartName, chartVersion, releaseName string, values string, options model.RenderOptions) (model.ManifestResponse, error)
+	RenderBatch(releases []model.BatchRelease) (model.BatchRender, error)
+	GetStringifiedManifests(repoName, chartName, chartVersion, hash string, withNotes bool) (string, error)
+	GetRenderedManifests(repoName, chartName, chartVersion, hash string) (model.ManifestResponse, error)
+	GetRenderValues(repoName, chartName, chartVersion, hash string) (map[string]interface{}, error)
 	GetChart(repoName string, chartName string, chartVersion string) (model.ChartDetail, error)
 	AnalyzeTemplate(templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error)
+	AnalyzeManifest(repoName, chartName, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestAnalyticsResult, error)
+	ValidateManifest(repoName, chartName, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestValidationResult, error)
+	GetChartReport(repoName, chartName, chartVersion string, values string, options model.RenderOptions) (model.ChartReport, error)
+	GetCompatibilityMatrix(repoName, chartName string) (model.CompatibilityMatrix, error)
+	DiffValues(repoName, chartName, fromVersion, toVersion string) (model.ValuesDiff, error)
+	DiffTemplates(repoName, chartName, fromVersion, toVersion string) (model.TemplatesDiff, error)
+	DiffManifests(repoName, chartName string, from, to model.RenderSpec) (model.ManifestDiff, error)
+	GetUpgradeImpact(repoName, chartName, fromVersion, toVersion string, values string, options model.RenderOptions) (model.UpgradeImpact, error)
+	GetImageInventory(repoName, chartName, chartVersion string, values string, options model.RenderOptions) (model.ImageInventory, error)
+	CompareImages(repoName, chartName, fromVersion, toVersion string, values string, options model.RenderOptions) (model.ImageComparison, error)
+	GetPolicies() ([]model.Policy, error)
+	SavePolicy(policy model.Policy) error
+	DeletePolicy(id string) error
+	CreateSnapshot(repoName, chartName, chartVersion string, req model.SnapshotRequest) (model.Snapshot, error)
+	RerenderSnapshot(id string, req model.SnapshotRerenderRequest) (model.Snapshot, error)
+	GetSnapshots() ([]model.Snapshot, error)
+	GetSnapshot(id string) (model.Snapshot, error)
+	GetStringifiedSnapshot(id string, withNotes bool) (string, error)
+	DeleteSnapshot(id string) error
 }
 
 type handler struct {
@@ -54,12 +82,33 @@ func (h *handler) GetCharts(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, charts)
 }
 
+func (h *handler) GetChangelog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := r.URL.Query().Get("from")
+	toVersion := r.URL.Query().Get("to")
+
+	changelog, err := h.service.GetChangelog(repoName, chartName, fromVersion, toVersion)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot get changelog of %s/%s", repoName, chartName), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, changelog)
+}
+
 func (h *handler) GetChart(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	repoName := vars["repo-name"]
 	chartName := vars["chart-name"]
 	chartVersion := vars["chart-version"]
 	kubeVersion := r.URL.Query().Get("kube-version")
+	format, err := reportFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	chart, err := h.service.GetChart(repoName, chartName, chartVersion)
 	if err != nil {
@@ -70,8 +119,13 @@ func (h *handler) GetChart(w http.ResponseWriter, r *http.Request) {
 
 	analyticsResults, err := h.service.AnalyzeTemplate(chart.Templates, kubeVersion)
 	if err != nil {
-		errMessage := fmt.Sprintf("error when analyzing the chart %s/%s:%s: %s", repoName, chartName, chartVersion, err)
-		respondWithError(w, http.StatusInternalServerError, errMessage)
+		respondWithServiceError(w, fmt.Sprintf("error when analyzing the chart %s/%s:%s", repoName, chartName, chartVersion), err)
+		return
+	}
+
+	if format != report.FormatJSON {
+		name := fmt.Sprintf("%s/%s:%s", repoName, chartName, chartVersion)
+		respondWithReport(w, format, name, report.FromTemplates("", analyticsResults))
 		return
 	}
 
@@ -120,11 +174,29 @@ func (h *handler) GetManifests(w http.ResponseWriter, r *http.Request) {
 	chartName := vars["chart-name"]
 	chartVersion := vars["chart-version"]
 	hash := vars["hash"]
+	withNotes := r.URL.Query().Get("notes") == "true"
 
-	manifest, err := h.service.GetStringifiedManifests(repoName, chartName, chartVersion, hash)
+	format, err := storedManifestFormat(r)
 	if err != nil {
-		errMessage := fmt.Sprintf("cannot get manifest: %s", err.Error())
-		respondWithError(w, http.StatusInternalServerError, errMessage)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if format != output.FormatYAML {
+		rendered, err := h.service.GetRenderedManifests(repoName, chartName, chartVersion, hash)
+		if err != nil {
+			respondWithServiceError(w, "cannot get manifest", err)
+			return
+		}
+
+		name := fmt.Sprintf("%s-%s", chartName, chartVersion)
+		respondWithManifests(w, format, name, append(rendered.CRDs, rendered.Manifests...))
+		return
+	}
+
+	manifest, err := h.service.GetStringifiedManifests(repoName, chartName, chartVersion, hash, withNotes)
+	if err != nil {
+		respondWithServiceError(w, "cannot get manifest", err)
 		return
 	}
 
@@ -132,9 +204,15 @@ func (h *handler) GetManifests(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) RenderManifests(w http.ResponseWriter, r *http.Request) {
+	format, err := manifestFormat(r, output.FormatJSON, output.FormatJSON, output.FormatYAML, output.FormatList, output.FormatTar, output.FormatKustomize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	decoder := json.NewDecoder(r.Body)
 	req := model.RenderRequest{}
-	err := decoder.Decode(&req)
+	err = decoder.Decode(&req)
 	if err != nil {
 		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
 		respondWithError(w, http.StatusBadRequest, errMessage)
@@ -147,14 +225,377 @@ func (h *handler) RenderManifests(w http.ResponseWriter, r *http.Request) {
 	chartName := vars["chart-name"]
 	chartVersion := vars["chart-version"]
 
-	manifests, err := h.service.RenderManifest(repoName, chartName, chartVersion, values)
+	manifests, err := h.service.RenderManifest(repoName, chartName, chartVersion, chartName, values, req.Options)
+	if err != nil {
+		respondWithServiceError(w, "cannot render manifest", err)
+		return
+	}
+
+	if format == output.FormatJSON {
+		respondWithJSON(w, http.StatusOK, manifests)
+		return
+	}
+
+	name := fmt.Sprintf("%s-%s", chartName, chartVersion)
+	respondWithManifests(w, format, name, append(manifests.CRDs, manifests.Manifests...))
+}
+
+func (h *handler) RenderBatch(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.BatchRenderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	batch, err := h.service.RenderBatch(req.Releases)
+	if err != nil {
+		respondWithServiceError(w, "cannot render batch", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, batch)
+}
+
+func (h *handler) GetManifestValues(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+	hash := vars["hash"]
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "yaml" && format != "json" {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("unsupported format %q, use yaml or json", format))
+		return
+	}
+
+	values, err := h.service.GetRenderValues(repoName, chartName, chartVersion, hash)
+	if err != nil {
+		respondWithServiceError(w, "cannot get values", err)
+		return
+	}
+
+	if format == "json" {
+		respondWithJSON(w, http.StatusOK, values)
+		return
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err = encoder.Encode(values)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("cannot encode values: %s", err.Error()))
+		return
+	}
+
+	respondWithText(w, http.StatusOK, buffer.String())
+}
+
+func (h *handler) AnalyzeManifests(w http.ResponseWriter, r *http.Request) {
+	format, err := reportFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	req := model.RenderRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+	kubeVersion := r.URL.Query().Get("kube-version")
+
+	results, err := h.service.AnalyzeManifest(repoName, chartName, chartVersion, req.Values, req.Options, kubeVersion)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("error when analyzing the manifests of %s/%s:%s", repoName, chartName, chartVersion), err)
+		return
+	}
+
+	if format != report.FormatJSON {
+		name := fmt.Sprintf("%s/%s:%s", repoName, chartName, chartVersion)
+		respondWithReport(w, format, name, report.FromManifests(results))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+func (h *handler) ValidateManifests(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.RenderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+	kubeVersion := r.URL.Query().Get("kube-version")
+
+	results, err := h.service.ValidateManifest(repoName, chartName, chartVersion, req.Values, req.Options, kubeVersion)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("error when validating the manifests of %s/%s:%s", repoName, chartName, chartVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+func (h *handler) GetChartReport(w http.ResponseWriter, r *http.Request) {
+	format, err := reportFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	req := model.RenderRequest{}
+	err = decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	chartReport, err := h.service.GetChartReport(repoName, chartName, chartVersion, req.Values, req.Options)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("error when checking the manifests of %s/%s:%s", repoName, chartName, chartVersion), err)
+		return
+	}
+
+	if format != report.FormatJSON {
+		name := fmt.Sprintf("%s/%s:%s", repoName, chartName, chartVersion)
+		respondWithReport(w, format, name, report.FromFindings(chartReport.Findings))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chartReport)
+}
+
+func (h *handler) GetCompatibilityMatrix(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+
+	matrix, err := h.service.GetCompatibilityMatrix(repoName, chartName)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot get compatibility matrix of %s/%s", repoName, chartName), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, matrix)
+}
+
+func (h *handler) DiffValues(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := vars["from-version"]
+	toVersion := vars["to-version"]
+
+	result, err := h.service.DiffValues(repoName, chartName, fromVersion, toVersion)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot diff values of %s/%s:%s and %s", repoName, chartName, fromVersion, toVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) DiffTemplates(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := vars["from-version"]
+	toVersion := vars["to-version"]
+
+	result, err := h.service.DiffTemplates(repoName, chartName, fromVersion, toVersion)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot diff templates of %s/%s:%s and %s", repoName, chartName, fromVersion, toVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) DiffManifests(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "text" {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("unsupported format %q, use json or text", format))
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	req := model.ManifestDiffRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	if req.From.Version == "" {
+		respondWithError(w, http.StatusBadRequest, "the version to compare from is required")
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+
+	result, err := h.service.DiffManifests(repoName, chartName, req.From, req.To)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot diff manifests of %s/%s", repoName, chartName), err)
+		return
+	}
+
+	if format == "text" {
+		respondWithText(w, http.StatusOK, diff.Unified(result))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) GetUpgradeImpact(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.RenderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := vars["from-version"]
+	toVersion := vars["to-version"]
+
+	impact, err := h.service.GetUpgradeImpact(repoName, chartName, fromVersion, toVersion, req.Values, req.Options)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("error when getting the upgrade impact of %s/%s from %s to %s", repoName, chartName, fromVersion, toVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, impact)
+}
+
+func (h *handler) GetImageInventory(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.RenderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	inventory, err := h.service.GetImageInventory(repoName, chartName, chartVersion, req.Values, req.Options)
 	if err != nil {
-		errMessage := fmt.Sprintf("cannot render manifest: %s", err.Error())
+		respondWithServiceError(w, fmt.Sprintf("error when listing the images of %s/%s:%s", repoName, chartName, chartVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, inventory)
+}
+
+func (h *handler) CompareImages(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.RenderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := vars["from-version"]
+	toVersion := vars["to-version"]
+
+	comparison, err := h.service.CompareImages(repoName, chartName, fromVersion, toVersion, req.Values, req.Options)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("error when comparing the images of %s/%s:%s and %s", repoName, chartName, fromVersion, toVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
+
+func (h *handler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.service.GetPolicies()
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot get policies: %s", err.Error())
 		respondWithError(w, http.StatusInternalServerError, errMessage)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, manifests)
+	respondWithJSON(w, http.StatusOK, policies)
+}
+
+func (h *handler) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	policy := model.Policy{}
+	err := decoder.Decode(&policy)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	policy.ID = vars["policy-id"]
+
+	err = h.service.SavePolicy(policy)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot save policy %s", policy.ID), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, policy)
+}
+
+func (h *handler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["policy-id"]
+
+	err := h.service.DeletePolicy(id)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot delete policy %s", id), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *handler) CORS(next http.Handler) http.Handler {
@@ -182,3 +623,110 @@ func (h *handler) LoggerMiddleware(next http.Handler) http.Handler {
 		return
 	})
 }
+
+func (h *handler) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.SnapshotRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	snapshot, err := h.service.CreateSnapshot(repoName, chartName, chartVersion, req)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot create snapshot of %s/%s:%s", repoName, chartName, chartVersion), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, snapshot)
+}
+
+func (h *handler) RerenderSnapshot(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	req := model.SnapshotRerenderRequest{}
+	err := decoder.Decode(&req)
+	if err != nil {
+		errMessage := fmt.Sprintf("cannot decode request body: %s", err.Error())
+		respondWithError(w, http.StatusBadRequest, errMessage)
+		return
+	}
+
+	id := mux.Vars(r)["snapshot-id"]
+	snapshot, err := h.service.RerenderSnapshot(id, req)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot rerender snapshot %s", id), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, snapshot)
+}
+
+func (h *handler) GetSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.service.GetSnapshots()
+	if err != nil {
+		respondWithServiceError(w, "cannot get snapshots", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, snapshots)
+}
+
+func (h *handler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["snapshot-id"]
+	snapshot, err := h.service.GetSnapshot(id)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot get snapshot %s", id), err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, snapshot)
+}
+
+func (h *handler) GetSnapshotManifests(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["snapshot-id"]
+	withNotes := r.URL.Query().Get("notes") == "true"
+
+	format, err := storedManifestFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if format != output.FormatYAML {
+		snapshot, err := h.service.GetSnapshot(id)
+		if err != nil {
+			respondWithServiceError(w, fmt.Sprintf("cannot get snapshot %s", id), err)
+			return
+		}
+
+		name := fmt.Sprintf("%s-%s", snapshot.Chart, snapshot.Version)
+		respondWithManifests(w, format, name, append(snapshot.CRDs, snapshot.Manifests...))
+		return
+	}
+
+	manifests, err := h.service.GetStringifiedSnapshot(id, withNotes)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot get snapshot %s", id), err)
+		return
+	}
+
+	respondWithText(w, http.StatusOK, manifests)
+}
+
+func (h *handler) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["snapshot-id"]
+	err := h.service.DeleteSnapshot(id)
+	if err != nil {
+		respondWithServiceError(w, fmt.Sprintf("cannot delete snapshot %s", id), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
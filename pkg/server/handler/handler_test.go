@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"chart-viewer/mocks"
 	"chart-viewer/pkg/model"
@@ -137,6 +139,272 @@ func Test_handler_GetCharts(t *testing.T) {
 	}
 }
 
+func Test_handler_RenderBatch(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	type args struct {
+		requestBody string
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		args           args
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:   "should return 200 with the result of every release",
+			fields: fields{service: new(mocks.Service)},
+			args:   args{requestBody: `{"releases": [{"name": "web", "repo": "stable", "chart": "app", "version": "1.0.0", "values": "replicaCount: 2"}, {"repo": "stable", "chart": "db"}]}`},
+			expectedResult: `
+				{
+					"succeeded": 1,
+					"failed": 1,
+					"releases": [
+						{"name": "web", "repo": "stable", "chart": "app", "version": "1.0.0", "url": "/api/v1/charts/manifests/stable/app/1.0.0/hash"},
+						{"name": "db", "repo": "stable", "chart": "db", "version": "", "error": "the repo, chart and version are required"}
+					],
+					"manifests": [
+						{"release": "web", "name": "deployment.yaml", "content": "kind: Deployment"}
+					],
+					"duplicates": []
+				}
+			`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				releases := []model.BatchRelease{
+					{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0", Values: "replicaCount: 2"},
+					{Repo: "stable", Chart: "db"},
+				}
+				batch := model.BatchRender{
+					Succeeded: 1,
+					Failed:    1,
+					Releases: []model.BatchReleaseResult{
+						{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0", URL: "/api/v1/charts/manifests/stable/app/1.0.0/hash"},
+						{Name: "db", Repo: "stable", Chart: "db", Error: "the repo, chart and version are required"},
+					},
+					Manifests: []model.ReleaseManifest{
+						{Release: "web", Manifest: model.Manifest{Name: "deployment.yaml", Content: "kind: Deployment"}},
+					},
+					Duplicates: []model.DuplicateObject{},
+				}
+				ff.service.On("RenderBatch", releases).Return(batch, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the batch is invalid",
+			fields:         fields{service: new(mocks.Service)},
+			args:           args{requestBody: `{"releases": []}`},
+			expectedResult: `{"error": "cannot render batch: invalid batch: at least one release is required"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields) {
+				err := fmt.Errorf("%w: at least one release is required", model.ErrInvalidBatch)
+				ff.service.On("RenderBatch", []model.BatchRelease{}).Return(model.BatchRender{}, err)
+			},
+		},
+		{
+			name:           "should return 400 when error to decode request body",
+			fields:         fields{service: new(mocks.Service)},
+			args:           args{requestBody: `malformed request body`},
+			expectedResult: `{"error": "cannot decode request body: invalid character 'm' looking for beginning of value"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields) {},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			args:           args{requestBody: `{"releases": [{"repo": "stable", "chart": "app", "version": "1.0.0"}]}`},
+			expectedResult: `{"error": "cannot render batch: cannot parse manifest deployment.yaml: yaml: line 2: mapping values are not allowed in this context"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				err := errors.New("cannot parse manifest deployment.yaml: yaml: line 2: mapping values are not allowed in this context")
+				ff.service.On("RenderBatch", []model.BatchRelease{{Repo: "stable", Chart: "app", Version: "1.0.0"}}).Return(model.BatchRender{}, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/render/batch", bytes.NewBufferString(tt.args.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/render/batch", appHandler.RenderBatch)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func Test_handler_GetManifestValues(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		query          string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:           "should return 200 with the values as YAML",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: "image:\n  tag: \"1.25\"\nreplicaCount: 2\n",
+			expectedCode:   http.StatusOK,
+			mockFn: func(ff fields) {
+				values := map[string]interface{}{"image": map[string]interface{}{"tag": "1.25"}, "replicaCount": 2}
+				ff.service.On("GetRenderValues", "stable", "app", "1.0.0", "hash").Return(values, nil)
+			},
+		},
+		{
+			name:           "should return 200 with the values as JSON",
+			fields:         fields{service: new(mocks.Service)},
+			query:          "?format=json",
+			expectedResult: `{"image":{"tag":"1.25"},"replicaCount":2}`,
+			expectedCode:   http.StatusOK,
+			mockFn: func(ff fields) {
+				values := map[string]interface{}{"image": map[string]interface{}{"tag": "1.25"}, "replicaCount": 2}
+				ff.service.On("GetRenderValues", "stable", "app", "1.0.0", "hash").Return(values, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the format is not supported",
+			fields:         fields{service: new(mocks.Service)},
+			query:          "?format=toml",
+			expectedResult: `{"error":"unsupported format \"toml\", use yaml or json"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields) {},
+		},
+		{
+			name:           "should return 404 when the render is not stored",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error":"cannot get values: render not found: hash"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("GetRenderValues", "stable", "app", "1.0.0", "hash").Return(nil, fmt.Errorf("%w: hash", model.ErrRenderNotFound))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("GET", "/charts/manifests/stable/app/1.0.0/hash/values"+tt.query, nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/manifests/{repo-name}/{chart-name}/{chart-version}/{hash}/values", appHandler.GetManifestValues)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedResult, recorder.Body.String())
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_GetChangelog(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		query          string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:   "should return 200 when success to get changelog",
+			fields: fields{service: new(mocks.Service)},
+			query:  "?from=1.9.0&to=1.10.0",
+			expectedResult: `{
+				"repo": "stable",
+				"chart": "app",
+				"from": "1.9.0",
+				"to": "1.10.0",
+				"versions": [
+					{"version": "1.10.0", "app_version": "2.1", "created": "2023-03-01T10:00:00Z", "changes": [{"kind": "fixed", "description": "Use the release namespace"}]},
+					{"version": "1.9.0", "app_version": "2.0", "created": "2023-02-01T10:00:00Z"}
+				]
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				changelog := model.Changelog{
+					Repo:  "stable",
+					Chart: "app",
+					From:  "1.9.0",
+					To:    "1.10.0",
+					Versions: []model.ChangelogEntry{
+						{Version: "1.10.0", AppVersion: "2.1", Created: "2023-03-01T10:00:00Z", Changes: []model.ChartChange{{Kind: "fixed", Description: "Use the release namespace"}}},
+						{Version: "1.9.0", AppVersion: "2.0", Created: "2023-02-01T10:00:00Z"},
+					},
+				}
+				ff.service.On("GetChangelog", "stable", "app", "1.9.0", "1.10.0").Return(changelog, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the version is invalid",
+			fields:         fields{service: new(mocks.Service)},
+			query:          "?from=latest",
+			expectedResult: `{"error": "cannot get changelog of stable/app: invalid chart version: \"latest\""}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields) {
+				ff.service.On("GetChangelog", "stable", "app", "latest", "").Return(model.Changelog{}, fmt.Errorf("%w: %q", model.ErrInvalidVersion, "latest"))
+			},
+		},
+		{
+			name:           "should return 404 when the chart is not in the repository",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot get changelog of stable/app: chart not found: stable/app"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("GetChangelog", "stable", "app", "", "").Return(model.Changelog{}, fmt.Errorf("%w: stable/app", model.ErrChartNotFound))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("GET", "/charts/changelog/stable/app"+tt.query, nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/changelog/{repo-name}/{chart-name}", appHandler.GetChangelog)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
 func Test_handler_GetChart(t *testing.T) {
 	type fields struct {
 		service *mocks.Service
@@ -412,7 +680,16 @@ metadata:
   labels:
     app.kubernetes.io/name: nginx
 `
-				ff.service.On("GetStringifiedManifests", "repo-name", "chart-name", "chart-version", "hash").Return(stringfiedManifests, nil)
+				ff.service.On("GetStringifiedManifests", "repo-name", "chart-name", "chart-version", "hash", false).Return(stringfiedManifests, nil)
+			},
+		},
+		{
+			name:           "should return 404 when the render is not stored",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error":"cannot get manifest: render not found: hash"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("GetStringifiedManifests", "repo-name", "chart-name", "chart-version", "hash", false).Return("", fmt.Errorf("%w: hash", model.ErrRenderNotFound))
 			},
 		},
 		{
@@ -421,7 +698,7 @@ metadata:
 			expectedResult: `{"error":"cannot get manifest: error"}`,
 			expectedCode:   http.StatusInternalServerError,
 			mockFn: func(ff fields) {
-				ff.service.On("GetStringifiedManifests", "repo-name", "chart-name", "chart-version", "hash").Return("", errors.New("error"))
+				ff.service.On("GetStringifiedManifests", "repo-name", "chart-name", "chart-version", "hash", false).Return("", errors.New("error"))
 			},
 		},
 	}
@@ -488,7 +765,44 @@ func Test_handler_RenderManifests(t *testing.T) {
 				req := model.RenderRequest{}
 				_ = json.Unmarshal([]byte(aa.requestBody), &req)
 
-				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", req.Values).Return(manifests, nil)
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", req.Values, req.Options).Return(manifests, nil)
+			},
+		},
+		{
+			name:   "should pass render options and return hook details",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `
+				{
+					"url" : "/charts/manifests/repo-name/chart-name/chart-version/hash",
+					"manifests": [
+						{
+							"name": "tests/test-connection.yaml",
+							"content": "kind: Pod",
+							"hook": {"events": ["test"], "weight": 1, "delete_policies": ["hook-succeeded"]}
+						}
+					]
+				}
+			`,
+			args:         args{requestBody: `{"values": "", "options": {"include_tests": true, "show_only": ["tests/*"]}}`},
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields, aa args) {
+				manifests := model.ManifestResponse{
+					URL: "/charts/manifests/repo-name/chart-name/chart-version/hash",
+					Manifests: []model.Manifest{
+						{
+							Name:    "tests/test-connection.yaml",
+							Content: "kind: Pod",
+							Hook: &model.ManifestHook{
+								Events:         []string{"test"},
+								Weight:         1,
+								DeletePolicies: []string{"hook-succeeded"},
+							},
+						},
+					},
+				}
+				options := model.RenderOptions{IncludeTests: true, ShowOnly: []string{"tests/*"}}
+
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", "", options).Return(manifests, nil)
 			},
 		},
 		{
@@ -499,6 +813,71 @@ func Test_handler_RenderManifests(t *testing.T) {
 			expectedCode:   http.StatusBadRequest,
 			mockFn:         func(ff fields, aa args) {},
 		},
+		{
+			name:           "should return 400 when show_only matches no template",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot render manifest: invalid show_only: could not find template ingress.yaml in chart"}`,
+			args:           args{requestBody: `{"values": "", "options": {"show_only": ["ingress.yaml"]}}`},
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields, aa args) {
+				options := model.RenderOptions{ShowOnly: []string{"ingress.yaml"}}
+				err := fmt.Errorf("%w: could not find template ingress.yaml in chart", model.ErrInvalidShowOnly)
+
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", "", options).Return(model.ManifestResponse{}, err)
+			},
+		},
+		{
+			name:   "should return 422 with diagnostics when a template fails to render",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `
+				{
+					"error": "cannot render manifest: parse error at (chart-name/templates/deployment.yaml:4): function \"nosuchfn\" not defined",
+					"diagnostics": [
+						{
+							"phase": "parse",
+							"template": "templates/deployment.yaml",
+							"line": 4,
+							"message": "function \"nosuchfn\" not defined",
+							"snippet": "> 4 |   name: {{ nosuchfn }}\n"
+						}
+					]
+				}
+			`,
+			args:         args{requestBody: `{"values": "affinity:{}"}`},
+			expectedCode: http.StatusUnprocessableEntity,
+			mockFn: func(ff fields, aa args) {
+				req := model.RenderRequest{}
+				_ = json.Unmarshal([]byte(aa.requestBody), &req)
+
+				renderErr := &model.RenderError{
+					Message: `parse error at (chart-name/templates/deployment.yaml:4): function "nosuchfn" not defined`,
+					Diagnostics: []model.RenderDiagnostic{
+						{
+							Phase:    "parse",
+							Template: "templates/deployment.yaml",
+							Line:     4,
+							Message:  `function "nosuchfn" not defined`,
+							Snippet:  "> 4 |   name: {{ nosuchfn }}\n",
+						},
+					},
+				}
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", req.Values, req.Options).Return(model.ManifestResponse{}, renderErr)
+			},
+		},
+		{
+			name:           "should return 400 when the post render pipeline is invalid",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot render manifest: invalid post render: patch 0: a json6902 patch needs a target"}`,
+			args:           args{requestBody: `{"values": "", "options": {"post_render": {"patches": [{"type": "json6902", "patch": "- op: remove\\n  path: /spec"}]}}}`},
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields, aa args) {
+				req := model.RenderRequest{}
+				_ = json.Unmarshal([]byte(aa.requestBody), &req)
+
+				err := fmt.Errorf("%w: patch 0: a json6902 patch needs a target", model.ErrInvalidPostRender)
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", req.Values, req.Options).Return(model.ManifestResponse{}, err)
+			},
+		},
 		{
 			name:           "should return 500 when error to decode request body",
 			fields:         fields{service: new(mocks.Service)},
@@ -509,7 +888,7 @@ func Test_handler_RenderManifests(t *testing.T) {
 				req := model.RenderRequest{}
 				_ = json.Unmarshal([]byte(aa.requestBody), &req)
 
-				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", req.Values).Return(model.ManifestResponse{}, errors.New("error"))
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", req.Values, req.Options).Return(model.ManifestResponse{}, errors.New("error"))
 			},
 		},
 	}
@@ -539,3 +918,1431 @@ func Test_handler_RenderManifests(t *testing.T) {
 		})
 	}
 }
+
+func Test_handler_ManifestFormats(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	type args struct {
+		method string
+		url    string
+		accept string
+	}
+	rendered := model.ManifestResponse{
+		URL: "/charts/manifests/repo-name/chart-name/chart-version/hash",
+		CRDs: []model.Manifest{
+			{Name: "crd.yaml", Template: "crds/crd.yaml", Content: "kind: CustomResourceDefinition"},
+		},
+		Manifests: []model.Manifest{
+			{Name: "service.yaml", Template: "templates/service.yaml", Content: "kind: Service"},
+		},
+	}
+	tests := []struct {
+		name                string
+		fields              fields
+		args                args
+		expectedResult      string
+		expectedCode        int
+		expectedContentType string
+		expectedDisposition string
+		mockFn              func(ff fields)
+	}{
+		{
+			name:                "should render a yaml stream of the CRDs and manifests when asked with the format parameter",
+			fields:              fields{service: new(mocks.Service)},
+			args:                args{method: "POST", url: "/charts/templates/render/repo-name/chart-name/chart-version?format=yaml"},
+			expectedResult:      "---\nkind: CustomResourceDefinition\n---\nkind: Service\n",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/plain",
+			mockFn: func(ff fields) {
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", "", model.RenderOptions{}).Return(rendered, nil)
+			},
+		},
+		{
+			name:                "should render a list with the crds when asked with the format parameter",
+			fields:              fields{service: new(mocks.Service)},
+			args:                args{method: "POST", url: "/charts/templates/render/repo-name/chart-name/chart-version?format=list"},
+			expectedResult:      "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"List\",\n  \"items\": [\n    {\n      \"kind\": \"CustomResourceDefinition\"\n    },\n    {\n      \"kind\": \"Service\"\n    }\n  ]\n}\n",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			mockFn: func(ff fields) {
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", "", model.RenderOptions{}).Return(rendered, nil)
+			},
+		},
+		{
+			name:                "should render an archive when asked with the accept header",
+			fields:              fields{service: new(mocks.Service)},
+			args:                args{method: "POST", url: "/charts/templates/render/repo-name/chart-name/chart-version", accept: "application/gzip"},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/gzip",
+			expectedDisposition: `attachment; filename="chart-name-chart-version-tar.tar.gz"`,
+			mockFn: func(ff fields) {
+				ff.service.On("RenderManifest", "repo-name", "chart-name", "chart-version", "chart-name", "", model.RenderOptions{}).Return(rendered, nil)
+			},
+		},
+		{
+			name:                "should return 400 when the format is not supported",
+			fields:              fields{service: new(mocks.Service)},
+			args:                args{method: "POST", url: "/charts/templates/render/repo-name/chart-name/chart-version?format=helm"},
+			expectedResult:      `{"error":"unsupported format \"helm\", use json, yaml, list, tar, kustomize"}`,
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: "application/json",
+			mockFn:              func(ff fields) {},
+		},
+		{
+			name:                "should return a kustomize bundle of a stored render",
+			fields:              fields{service: new(mocks.Service)},
+			args:                args{method: "GET", url: "/charts/manifests/repo-name/chart-name/chart-version/hash?format=kustomize"},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/gzip",
+			expectedDisposition: `attachment; filename="chart-name-chart-version-kustomize.tar.gz"`,
+			mockFn: func(ff fields) {
+				ff.service.On("GetRenderedManifests", "repo-name", "chart-name", "chart-version", "hash").Return(rendered, nil)
+			},
+		},
+		{
+			name:                "should return 404 when the stored render is not found",
+			fields:              fields{service: new(mocks.Service)},
+			args:                args{method: "GET", url: "/charts/manifests/repo-name/chart-name/chart-version/hash", accept: "application/json"},
+			expectedResult:      `{"error":"cannot get manifest: render not found"}`,
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: "application/json",
+			mockFn: func(ff fields) {
+				ff.service.On("GetRenderedManifests", "repo-name", "chart-name", "chart-version", "hash").Return(model.ManifestResponse{}, model.ErrRenderNotFound)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest(tt.args.method, tt.args.url, bytes.NewBufferString(`{"values": ""}`))
+			assert.NoError(t, err)
+			if tt.args.accept != "" {
+				req.Header.Set("Accept", tt.args.accept)
+			}
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/templates/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifests)
+			router.HandleFunc("/charts/manifests/{repo-name}/{chart-name}/{chart-version}/{hash}", appHandler.GetManifests)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if tt.expectedDisposition != "" {
+				assert.Equal(t, []byte{0x1f, 0x8b}, content[:2])
+			} else {
+				assert.Equal(t, tt.expectedResult, string(content))
+			}
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedDisposition, recorder.Header().Get("Content-Disposition"))
+		})
+	}
+}
+
+func Test_handler_AnalyzeManifests(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	type args struct {
+		requestBody string
+		format      string
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		args           args
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields, aa args)
+	}{
+		{
+			name:   "should return 200 when success to analyze manifests",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `[
+				{
+					"name": "ingress.yaml",
+					"template": "templates/ingress.yaml",
+					"content": "apiVersion: extensions/v1beta1\nkind: Ingress",
+					"compatible": false,
+					"resources": [
+						{"api_version": "extensions/v1beta1", "kind": "Ingress", "name": "app", "compatible": false}
+					]
+				}
+			]`,
+			args:         args{requestBody: `{"values": "ingress:\n  enabled: true"}`},
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields, aa args) {
+				results := []model.ManifestAnalyticsResult{
+					{
+						Manifest: model.Manifest{
+							Name:     "ingress.yaml",
+							Template: "templates/ingress.yaml",
+							Content:  "apiVersion: extensions/v1beta1\nkind: Ingress",
+						},
+						Compatible: false,
+						Resources: []model.ResourceAnalytics{
+							{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "app", Compatible: false},
+						},
+					},
+				}
+				ff.service.On("AnalyzeManifest", "repo-name", "chart-name", "chart-version", "ingress:\n  enabled: true", model.RenderOptions{}, "1.22").Return(results, nil)
+			},
+		},
+		{
+			name:   "should return sarif when the format is sarif",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `{
+				"version": "2.1.0",
+				"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+				"runs": [
+					{
+						"tool": {
+							"driver": {
+								"name": "chart-viewer",
+								"informationUri": "https://github.com/ecojuntak/chart-viewer",
+								"rules": [{"id": "unavailable-api", "shortDescription": {"text": "The API version is not served by the Kubernetes version"}}]
+							}
+						},
+						"results": [
+							{
+								"ruleId": "unavailable-api",
+								"ruleIndex": 0,
+								"level": "error",
+								"message": {"text": "extensions/v1beta1 Ingress is not served by the Kubernetes version"},
+								"locations": [{"physicalLocation": {"artifactLocation": {"uri": "templates/ingress.yaml"}}}]
+							}
+						]
+					}
+				]
+			}`,
+			args:         args{requestBody: `{"values": ""}`, format: "sarif"},
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields, aa args) {
+				results := []model.ManifestAnalyticsResult{
+					{
+						Manifest:   model.Manifest{Name: "ingress.yaml", Template: "templates/ingress.yaml"},
+						Compatible: false,
+						Resources: []model.ResourceAnalytics{
+							{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "app", Compatible: false},
+						},
+					},
+				}
+				ff.service.On("AnalyzeManifest", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}, "1.22").Return(results, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the format is not supported",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "unsupported format \"html\", use json, sarif or junit"}`,
+			args:           args{requestBody: `{"values": ""}`, format: "html"},
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields, aa args) {},
+		},
+		{
+			name:           "should return 400 when error to decode request body",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot decode request body: invalid character 'm' looking for beginning of value"}`,
+			args:           args{requestBody: `malformed request body`},
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields, aa args) {},
+		},
+		{
+			name:           "should return 400 when the kubernetes version is unknown",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "error when analyzing the manifests of repo-name/chart-name:chart-version: unknown kubernetes version: \"1.22\""}`,
+			args:           args{requestBody: `{"values": ""}`},
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields, aa args) {
+				ff.service.On("AnalyzeManifest", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}, "1.22").Return(nil, fmt.Errorf("%w: %q", model.ErrUnknownKubeVersion, "1.22"))
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "error when analyzing the manifests of repo-name/chart-name:chart-version: error"}`,
+			args:           args{requestBody: `{"values": ""}`},
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields, aa args) {
+				ff.service.On("AnalyzeManifest", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}, "1.22").Return(nil, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields, tt.args)
+
+			request := []byte(tt.args.requestBody)
+			url := "/charts/analyze/repo-name/chart-name/chart-version?kube-version=1.22"
+			if tt.args.format != "" {
+				url += "&format=" + tt.args.format
+			}
+			req, err := http.NewRequest("POST", url, bytes.NewBuffer(request))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/analyze/{repo-name}/{chart-name}/{chart-version}", appHandler.AnalyzeManifests)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_GetCompatibilityMatrix(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:   "should return 200 when success to get compatibility matrix",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `{
+				"repo": "repo-name",
+				"chart": "chart-name",
+				"kube_versions": ["1.16", "1.22"],
+				"versions": [
+					{"version": "v0.0.1", "min_kube_version": "1.16", "max_kube_version": "1.16", "kube_versions": ["1.16"], "unknown_kube_versions": ["1.22"]}
+				]
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				ff.service.On("GetCompatibilityMatrix", "repo-name", "chart-name").Return(model.CompatibilityMatrix{
+					Repo:         "repo-name",
+					Chart:        "chart-name",
+					KubeVersions: []string{"1.16", "1.22"},
+					Versions: []model.ChartCompatibility{
+						{Version: "v0.0.1", MinKubeVersion: "1.16", MaxKubeVersion: "1.16", KubeVersions: []string{"1.16"}, UnknownKubeVersions: []string{"1.22"}},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:           "should return 404 when the chart does not exist",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot get compatibility matrix of repo-name/chart-name: chart not found: chart-name in repo-name"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("GetCompatibilityMatrix", "repo-name", "chart-name").Return(model.CompatibilityMatrix{}, fmt.Errorf("%w: chart-name in repo-name", model.ErrChartNotFound))
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot get compatibility matrix of repo-name/chart-name: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("GetCompatibilityMatrix", "repo-name", "chart-name").Return(model.CompatibilityMatrix{}, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("GET", "/charts/compatibility/repo-name/chart-name", nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/compatibility/{repo-name}/{chart-name}", appHandler.GetCompatibilityMatrix)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_ValidateManifests(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:        "should return 200 with the validation result of every manifest",
+			fields:      fields{service: new(mocks.Service)},
+			requestBody: `{"values": ""}`,
+			expectedResult: `[
+				{
+					"name": "deployment.yaml",
+					"api_version": "apps/v1",
+					"kind": "Deployment",
+					"resource": "app",
+					"status": "invalid",
+					"errors": [{"field": "spec.replicas", "message": "Invalid type. Expected: [integer,null], given: string"}]
+				}
+			]`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				ff.service.On("ValidateManifest", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}, "1.22").Return([]model.ManifestValidationResult{
+					{
+						Name:       "deployment.yaml",
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Resource:   "app",
+						Status:     "invalid",
+						Errors: []model.ValidationError{
+							{Field: "spec.replicas", Message: "Invalid type. Expected: [integer,null], given: string"},
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the kubernetes version is unknown",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": ""}`,
+			expectedResult: `{"error": "error when validating the manifests of repo-name/chart-name:chart-version: unknown kubernetes version: \"1.22\""}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields) {
+				ff.service.On("ValidateManifest", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}, "1.22").Return(nil, fmt.Errorf("%w: %q", model.ErrUnknownKubeVersion, "1.22"))
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": ""}`,
+			expectedResult: `{"error": "error when validating the manifests of repo-name/chart-name:chart-version: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("ValidateManifest", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}, "1.22").Return(nil, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/charts/validate/repo-name/chart-name/chart-version?kube-version=1.22", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/validate/{repo-name}/{chart-name}/{chart-version}", appHandler.ValidateManifests)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_GetChartReport(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:        "should return 200 with the report of the chart version",
+			fields:      fields{service: new(mocks.Service)},
+			requestBody: `{"values": ""}`,
+			expectedResult: `{
+				"repo": "repo-name",
+				"chart": "chart-name",
+				"version": "chart-version",
+				"summary": {"total": 1, "by_severity": {"high": 1}, "by_rule": {"privileged-container": 1}},
+				"findings": [
+					{
+						"rule_id": "privileged-container",
+						"severity": "high",
+						"manifest": "deployment.yaml",
+						"kind": "Deployment",
+						"resource": "app",
+						"container": "app",
+						"message": "container runs in privileged mode"
+					}
+				]
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				ff.service.On("GetChartReport", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}).Return(model.ChartReport{
+					Repo:    "repo-name",
+					Chart:   "chart-name",
+					Version: "chart-version",
+					Summary: model.ReportSummary{
+						Total:      1,
+						BySeverity: map[string]int{"high": 1},
+						ByRule:     map[string]int{"privileged-container": 1},
+					},
+					Findings: []model.RuleFinding{
+						{RuleID: "privileged-container", Severity: "high", Manifest: "deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container runs in privileged mode"},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": ""}`,
+			expectedResult: `{"error": "error when checking the manifests of repo-name/chart-name:chart-version: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("GetChartReport", "repo-name", "chart-name", "chart-version", "", model.RenderOptions{}).Return(model.ChartReport{}, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/charts/report/repo-name/chart-name/chart-version", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/report/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChartReport)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_SavePolicy(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:           "should return 200 when the policy is saved",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"severity": "high", "kinds": ["Service"], "expression": "object.spec.type != 'LoadBalancer'"}`,
+			expectedResult: `{"id": "no-load-balancer", "severity": "high", "kinds": ["Service"], "expression": "object.spec.type != 'LoadBalancer'"}`,
+			expectedCode:   http.StatusOK,
+			mockFn: func(ff fields) {
+				ff.service.On("SavePolicy", model.Policy{ID: "no-load-balancer", Severity: "high", Kinds: []string{"Service"}, Expression: "object.spec.type != 'LoadBalancer'"}).Return(nil)
+			},
+		},
+		{
+			name:           "should return 400 when the policy is invalid",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"severity": "high", "expression": "object."}`,
+			expectedResult: `{"error": "cannot save policy no-load-balancer: invalid policy: no-load-balancer: syntax error"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields) {
+				ff.service.On("SavePolicy", model.Policy{ID: "no-load-balancer", Severity: "high", Expression: "object."}).Return(fmt.Errorf("%w: no-load-balancer: syntax error", model.ErrInvalidPolicy))
+			},
+		},
+		{
+			name:           "should return 400 when error to decode request body",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `malformed request body`,
+			expectedResult: `{"error": "cannot decode request body: invalid character 'm' looking for beginning of value"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("PUT", "/policies/no-load-balancer", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/policies/{policy-id}", appHandler.SavePolicy)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_DeletePolicy(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		expectedCode int
+		mockFn       func(ff fields)
+	}{
+		{
+			name:         "should return 204 when the policy is deleted",
+			fields:       fields{service: new(mocks.Service)},
+			expectedCode: http.StatusNoContent,
+			mockFn: func(ff fields) {
+				ff.service.On("DeletePolicy", "no-load-balancer").Return(nil)
+			},
+		},
+		{
+			name:         "should return 404 when the policy does not exist",
+			fields:       fields{service: new(mocks.Service)},
+			expectedCode: http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("DeletePolicy", "no-load-balancer").Return(fmt.Errorf("%w: no-load-balancer", model.ErrPolicyNotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("DELETE", "/policies/no-load-balancer", nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/policies/{policy-id}", appHandler.DeletePolicy)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_CompareImages(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:        "should return 200 when success to compare images",
+			fields:      fields{service: new(mocks.Service)},
+			requestBody: `{"values": ""}`,
+			expectedResult: `{
+				"repo": "stable",
+				"chart": "app",
+				"from": "1.0.0",
+				"to": "1.1.0",
+				"added": [],
+				"removed": [],
+				"changed": [
+					{
+						"registry": "docker.io",
+						"repository": "library/nginx",
+						"from": {"image": "docker.io/library/nginx:1.24", "registry": "docker.io", "repository": "library/nginx", "tag": "1.24"},
+						"to": {"image": "docker.io/library/nginx:1.25", "registry": "docker.io", "repository": "library/nginx", "tag": "1.25"}
+					}
+				],
+				"unchanged": []
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				comparison := model.ImageComparison{
+					Repo:    "stable",
+					Chart:   "app",
+					From:    "1.0.0",
+					To:      "1.1.0",
+					Added:   []model.ContainerImage{},
+					Removed: []model.ContainerImage{},
+					Changed: []model.ImageChange{
+						{
+							Registry:   "docker.io",
+							Repository: "library/nginx",
+							From:       model.ContainerImage{Image: "docker.io/library/nginx:1.24", Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"},
+							To:         model.ContainerImage{Image: "docker.io/library/nginx:1.25", Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+						},
+					},
+					Unchanged: []model.ContainerImage{},
+				}
+				ff.service.On("CompareImages", "stable", "app", "1.0.0", "1.1.0", "", model.RenderOptions{}).Return(comparison, nil)
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": ""}`,
+			expectedResult: `{"error": "error when comparing the images of stable/app:1.0.0 and 1.1.0: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("CompareImages", "stable", "app", "1.0.0", "1.1.0", "", model.RenderOptions{}).Return(model.ImageComparison{}, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/charts/images/compare/stable/app/1.0.0/1.1.0", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/images/compare/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.CompareImages)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_GetUpgradeImpact(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:        "should return 200 when success to get the upgrade impact",
+			fields:      fields{service: new(mocks.Service)},
+			requestBody: `{"values": "ingress: true"}`,
+			expectedResult: `{
+				"repo": "stable",
+				"chart": "app",
+				"from": "1.0.0",
+				"to": "1.1.0",
+				"values": [{"path": "/ingress", "value": true, "renamed_to": "/networking/ingress"}],
+				"recreated": [{"api_version": "apps/v1", "kind": "Deployment", "name": "app", "manifest": "deployment.yaml", "fields": ["/spec/selector"]}],
+				"removed_resources": [{"api_version": "batch/v1", "kind": "Job", "name": "migrate"}],
+				"image_changes": [],
+				"added_images": []
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				impact := model.UpgradeImpact{
+					Repo:   "stable",
+					Chart:  "app",
+					From:   "1.0.0",
+					To:     "1.1.0",
+					Values: []model.ValueImpact{{Path: "/ingress", Value: true, RenamedTo: "/networking/ingress"}},
+					Recreated: []model.RecreateImpact{{
+						ObjectIdentity: model.ObjectIdentity{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+						Manifest:       "deployment.yaml",
+						Fields:         []string{"/spec/selector"},
+					}},
+					RemovedResources: []model.ObjectIdentity{{APIVersion: "batch/v1", Kind: "Job", Name: "migrate"}},
+					ImageChanges:     []model.ImageChange{},
+					AddedImages:      []model.ContainerImage{},
+				}
+				ff.service.On("GetUpgradeImpact", "stable", "app", "1.0.0", "1.1.0", "ingress: true", model.RenderOptions{}).Return(impact, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the request body is invalid",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": `,
+			expectedResult: `{"error": "cannot decode request body: unexpected EOF"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields) {},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": ""}`,
+			expectedResult: `{"error": "error when getting the upgrade impact of stable/app from 1.0.0 to 1.1.0: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("GetUpgradeImpact", "stable", "app", "1.0.0", "1.1.0", "", model.RenderOptions{}).Return(model.UpgradeImpact{}, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/charts/upgrade-impact/stable/app/1.0.0/1.1.0", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/upgrade-impact/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.GetUpgradeImpact)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_DiffValues(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:   "should return 200 when success to diff values",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `{
+				"repo": "stable",
+				"chart": "app",
+				"from": "1.0.0",
+				"to": "1.1.0",
+				"added": [{"path": "/ingress/className", "new": "nginx"}],
+				"removed": [{"path": "/legacy", "old": true}],
+				"changed": [{"path": "/ingress/enabled", "old": false, "new": true}]
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				result := model.ValuesDiff{
+					Repo:    "stable",
+					Chart:   "app",
+					From:    "1.0.0",
+					To:      "1.1.0",
+					Added:   []model.ValueChange{{Path: "/ingress/className", New: "nginx"}},
+					Removed: []model.ValueChange{{Path: "/legacy", Old: true}},
+					Changed: []model.ValueChange{{Path: "/ingress/enabled", Old: false, New: true}},
+				}
+				ff.service.On("DiffValues", "stable", "app", "1.0.0", "1.1.0").Return(result, nil)
+			},
+		},
+		{
+			name:           "should return 404 when the chart is not found",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot diff values of stable/app:1.0.0 and 1.1.0: chart not found: stable/app"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("DiffValues", "stable", "app", "1.0.0", "1.1.0").Return(model.ValuesDiff{}, fmt.Errorf("%w: stable/app", model.ErrChartNotFound))
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot diff values of stable/app:1.0.0 and 1.1.0: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("DiffValues", "stable", "app", "1.0.0", "1.1.0").Return(model.ValuesDiff{}, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("GET", "/charts/diff/values/stable/app/1.0.0/1.1.0", nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/diff/values/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.DiffValues)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_DiffTemplates(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:   "should return 200 when success to diff templates",
+			fields: fields{service: new(mocks.Service)},
+			expectedResult: `{
+				"repo": "stable",
+				"chart": "app",
+				"from": "1.0.0",
+				"to": "1.1.0",
+				"added": [],
+				"removed": [],
+				"modified": [{"name": "templates/_helpers.tpl", "modified_definitions": ["app.labels"], "diff": "<<PRESENCE>>"}],
+				"renamed": [{"name": "templates/service.yaml", "old_name": "templates/svc.yaml", "similarity": 100, "diff": ""}],
+				"unchanged": 2
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				result := model.TemplatesDiff{
+					Repo:    "stable",
+					Chart:   "app",
+					From:    "1.0.0",
+					To:      "1.1.0",
+					Added:   []model.TemplateChange{},
+					Removed: []model.TemplateChange{},
+					Modified: []model.TemplateChange{{
+						Name:                "templates/_helpers.tpl",
+						ModifiedDefinitions: []string{"app.labels"},
+						Diff:                "--- a/templates/_helpers.tpl\n+++ b/templates/_helpers.tpl\n",
+					}},
+					Renamed:   []model.TemplateChange{{Name: "templates/service.yaml", OldName: "templates/svc.yaml", Similarity: 100}},
+					Unchanged: 2,
+				}
+				ff.service.On("DiffTemplates", "stable", "app", "1.0.0", "1.1.0").Return(result, nil)
+			},
+		},
+		{
+			name:           "should return 404 when the chart is not found",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot diff templates of stable/app:1.0.0 and 1.1.0: chart not found: stable/app"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("DiffTemplates", "stable", "app", "1.0.0", "1.1.0").Return(model.TemplatesDiff{}, fmt.Errorf("%w: stable/app", model.ErrChartNotFound))
+			},
+		},
+		{
+			name:           "should return 500 when service layer return error",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error": "cannot diff templates of stable/app:1.0.0 and 1.1.0: error"}`,
+			expectedCode:   http.StatusInternalServerError,
+			mockFn: func(ff fields) {
+				ff.service.On("DiffTemplates", "stable", "app", "1.0.0", "1.1.0").Return(model.TemplatesDiff{}, errors.New("error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("GET", "/charts/diff/templates/stable/app/1.0.0/1.1.0", nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/diff/templates/{repo-name}/{chart-name}/{from-version}/{to-version}", appHandler.DiffTemplates)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_DiffManifests(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	type args struct {
+		requestBody string
+		format      string
+	}
+	result := model.ManifestDiff{
+		Repo:    "stable",
+		Chart:   "app",
+		From:    "1.0.0",
+		To:      "1.1.0",
+		Added:   []model.ObjectDiff{},
+		Removed: []model.ObjectDiff{},
+		Changed: []model.ObjectDiff{
+			{
+				ObjectIdentity: model.ObjectIdentity{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				Manifest:       "deployment.yaml",
+				Changed:        []model.ValueChange{{Path: "/spec/replicas", Old: 1, New: 2}},
+				Diff:           "--- a/Deployment/app\n+++ b/Deployment/app\n@@ -1 +1 @@\n-replicas: 1\n+replicas: 2\n",
+			},
+		},
+		Unchanged: 2,
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		args           args
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:   "should return 200 when success to diff manifests",
+			fields: fields{service: new(mocks.Service)},
+			args:   args{requestBody: `{"from": {"version": "1.0.0"}, "to": {"version": "1.1.0"}}`},
+			expectedResult: `{
+				"repo": "stable",
+				"chart": "app",
+				"from": "1.0.0",
+				"to": "1.1.0",
+				"added": [],
+				"removed": [],
+				"changed": [
+					{
+						"api_version": "apps/v1",
+						"kind": "Deployment",
+						"name": "app",
+						"manifest": "deployment.yaml",
+						"changed": [{"path": "/spec/replicas", "old": 1, "new": 2}],
+						"diff": "--- a/Deployment/app\n+++ b/Deployment/app\n@@ -1 +1 @@\n-replicas: 1\n+replicas: 2\n"
+					}
+				],
+				"unchanged": 2
+			}`,
+			expectedCode: http.StatusOK,
+			mockFn: func(ff fields) {
+				ff.service.On("DiffManifests", "stable", "app", model.RenderSpec{Version: "1.0.0"}, model.RenderSpec{Version: "1.1.0"}).Return(result, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the version to compare from is missing",
+			fields:         fields{service: new(mocks.Service)},
+			args:           args{requestBody: `{"to": {"version": "1.1.0"}}`},
+			expectedResult: `{"error": "the version to compare from is required"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields) {},
+		},
+		{
+			name:           "should return 400 when the format is not supported",
+			fields:         fields{service: new(mocks.Service)},
+			args:           args{requestBody: `{"from": {"version": "1.0.0"}}`, format: "html"},
+			expectedResult: `{"error": "unsupported format \"html\", use json or text"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn:         func(ff fields) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			url := "/charts/diff/manifests/stable/app"
+			if tt.args.format != "" {
+				url += "?format=" + tt.args.format
+			}
+			req, err := http.NewRequest("POST", url, bytes.NewBufferString(tt.args.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/charts/diff/manifests/{repo-name}/{chart-name}", appHandler.DiffManifests)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+
+	t.Run("should return the unified diff when the format is text", func(t *testing.T) {
+		svc := new(mocks.Service)
+		svc.On("DiffManifests", "stable", "app", model.RenderSpec{Version: "1.0.0"}, model.RenderSpec{Version: "1.1.0"}).Return(result, nil)
+
+		req, err := http.NewRequest("POST", "/charts/diff/manifests/stable/app?format=text", bytes.NewBufferString(`{"from": {"version": "1.0.0"}, "to": {"version": "1.1.0"}}`))
+		assert.NoError(t, err)
+
+		appHandler := handler.NewHandler(svc)
+		recorder := httptest.NewRecorder()
+		router := mux.NewRouter()
+		router.HandleFunc("/charts/diff/manifests/{repo-name}/{chart-name}", appHandler.DiffManifests)
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "--- a/Deployment/app\n+++ b/Deployment/app\n@@ -1 +1 @@\n-replicas: 1\n+replicas: 2\n", recorder.Body.String())
+	})
+}
+
+func Test_handler_CreateSnapshot(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	createdAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(72 * time.Hour)
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:        "should return 201 when the snapshot is created",
+			fields:      fields{service: new(mocks.Service)},
+			requestBody: `{"name": "incident 42", "author": "oncall", "values": "replicaCount: 2", "expires_in": "72h"}`,
+			expectedResult: `{
+				"id": "0123456789abcdef",
+				"name": "incident 42",
+				"author": "oncall",
+				"repo": "stable",
+				"chart": "app",
+				"version": "1.0.0",
+				"values": "replicaCount: 2",
+				"options": {},
+				"url": "/api/v1/snapshots/0123456789abcdef",
+				"created_at": "2023-01-01T10:00:00Z",
+				"expires_at": "2023-01-04T10:00:00Z",
+				"manifests": [{"name": "service.yaml", "content": "kind: Service"}]
+			}`,
+			expectedCode: http.StatusCreated,
+			mockFn: func(ff fields) {
+				req := model.SnapshotRequest{Name: "incident 42", Author: "oncall", Values: "replicaCount: 2", ExpiresIn: "72h"}
+				snapshot := model.Snapshot{
+					ID:        "0123456789abcdef",
+					Name:      "incident 42",
+					Author:    "oncall",
+					Repo:      "stable",
+					Chart:     "app",
+					Version:   "1.0.0",
+					Values:    "replicaCount: 2",
+					URL:       "/api/v1/snapshots/0123456789abcdef",
+					CreatedAt: createdAt,
+					ExpiresAt: &expiresAt,
+					Manifests: []model.Manifest{{Name: "service.yaml", Content: "kind: Service"}},
+				}
+				ff.service.On("CreateSnapshot", "stable", "app", "1.0.0", req).Return(snapshot, nil)
+			},
+		},
+		{
+			name:           "should return 400 when the snapshot is invalid",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"values": "replicaCount: 2"}`,
+			expectedResult: `{"error": "cannot create snapshot of stable/app:1.0.0: invalid snapshot: the name is required"}`,
+			expectedCode:   http.StatusBadRequest,
+			mockFn: func(ff fields) {
+				req := model.SnapshotRequest{Values: "replicaCount: 2"}
+				ff.service.On("CreateSnapshot", "stable", "app", "1.0.0", req).Return(model.Snapshot{}, fmt.Errorf("%w: the name is required", model.ErrInvalidSnapshot))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/snapshots/stable/app/1.0.0", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/snapshots/{repo-name}/{chart-name}/{chart-version}", appHandler.CreateSnapshot)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_RerenderSnapshot(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		requestBody    string
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:        "should return 201 when the snapshot is rendered again",
+			fields:      fields{service: new(mocks.Service)},
+			requestBody: `{"version": "1.1.0"}`,
+			expectedResult: `{
+				"id": "fedcba9876543210",
+				"name": "incident 42",
+				"repo": "stable",
+				"chart": "app",
+				"version": "1.1.0",
+				"values": "",
+				"options": {},
+				"parent_id": "0123456789abcdef",
+				"url": "/api/v1/snapshots/fedcba9876543210",
+				"created_at": "2023-01-01T10:00:00Z"
+			}`,
+			expectedCode: http.StatusCreated,
+			mockFn: func(ff fields) {
+				snapshot := model.Snapshot{
+					ID:        "fedcba9876543210",
+					Name:      "incident 42",
+					Repo:      "stable",
+					Chart:     "app",
+					Version:   "1.1.0",
+					ParentID:  "0123456789abcdef",
+					URL:       "/api/v1/snapshots/fedcba9876543210",
+					CreatedAt: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+				}
+				ff.service.On("RerenderSnapshot", "0123456789abcdef", model.SnapshotRerenderRequest{Version: "1.1.0"}).Return(snapshot, nil)
+			},
+		},
+		{
+			name:           "should return 404 when the snapshot does not exist",
+			fields:         fields{service: new(mocks.Service)},
+			requestBody:    `{"version": "1.1.0"}`,
+			expectedResult: `{"error": "cannot rerender snapshot 0123456789abcdef: snapshot not found: 0123456789abcdef"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("RerenderSnapshot", "0123456789abcdef", model.SnapshotRerenderRequest{Version: "1.1.0"}).Return(model.Snapshot{}, fmt.Errorf("%w: 0123456789abcdef", model.ErrSnapshotNotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("POST", "/snapshots/0123456789abcdef/rerender", bytes.NewBufferString(tt.requestBody))
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/snapshots/{snapshot-id}/rerender", appHandler.RerenderSnapshot)
+			router.ServeHTTP(recorder, req)
+
+			content, err := io.ReadAll(recorder.Body)
+			if err != nil {
+				t.Error(err)
+			}
+
+			ja := jsonassert.New(t)
+			ja.Assertf(string(content), tt.expectedResult)
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_GetSnapshots(t *testing.T) {
+	service := new(mocks.Service)
+	service.On("GetSnapshots").Return([]model.Snapshot{
+		{ID: "0123456789abcdef", Name: "incident 42", Repo: "stable", Chart: "app", Version: "1.0.0", URL: "/api/v1/snapshots/0123456789abcdef", CreatedAt: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	req, err := http.NewRequest("GET", "/snapshots", nil)
+	assert.NoError(t, err)
+
+	appHandler := handler.NewHandler(service)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(appHandler.GetSnapshots).ServeHTTP(recorder, req)
+
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `[{
+		"id": "0123456789abcdef",
+		"name": "incident 42",
+		"repo": "stable",
+		"chart": "app",
+		"version": "1.0.0",
+		"values": "",
+		"options": {},
+		"url": "/api/v1/snapshots/0123456789abcdef",
+		"created_at": "2023-01-01T10:00:00Z"
+	}]`)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_handler_GetSnapshotManifests(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		expectedResult string
+		expectedCode   int
+		mockFn         func(ff fields)
+	}{
+		{
+			name:           "should return 200 with the manifests of the snapshot",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: "---\nkind: Service\n",
+			expectedCode:   http.StatusOK,
+			mockFn: func(ff fields) {
+				ff.service.On("GetStringifiedSnapshot", "0123456789abcdef", false).Return("---\nkind: Service\n", nil)
+			},
+		},
+		{
+			name:           "should return 404 when the snapshot expired",
+			fields:         fields{service: new(mocks.Service)},
+			expectedResult: `{"error":"cannot get snapshot 0123456789abcdef: snapshot not found: 0123456789abcdef"}`,
+			expectedCode:   http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("GetStringifiedSnapshot", "0123456789abcdef", false).Return("", fmt.Errorf("%w: 0123456789abcdef", model.ErrSnapshotNotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("GET", "/snapshots/0123456789abcdef/manifests", nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/snapshots/{snapshot-id}/manifests", appHandler.GetSnapshotManifests)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedResult, recorder.Body.String())
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
+
+func Test_handler_DeleteSnapshot(t *testing.T) {
+	type fields struct {
+		service *mocks.Service
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		expectedCode int
+		mockFn       func(ff fields)
+	}{
+		{
+			name:         "should return 204 when the snapshot is deleted",
+			fields:       fields{service: new(mocks.Service)},
+			expectedCode: http.StatusNoContent,
+			mockFn: func(ff fields) {
+				ff.service.On("DeleteSnapshot", "0123456789abcdef").Return(nil)
+			},
+		},
+		{
+			name:         "should return 404 when the snapshot does not exist",
+			fields:       fields{service: new(mocks.Service)},
+			expectedCode: http.StatusNotFound,
+			mockFn: func(ff fields) {
+				ff.service.On("DeleteSnapshot", "0123456789abcdef").Return(fmt.Errorf("%w: 0123456789abcdef", model.ErrSnapshotNotFound))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			req, err := http.NewRequest("DELETE", "/snapshots/0123456789abcdef", nil)
+			assert.NoError(t, err)
+
+			appHandler := handler.NewHandler(tt.fields.service)
+			recorder := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/snapshots/{snapshot-id}", appHandler.DeleteSnapshot)
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, recorder.Code, tt.expectedCode)
+		})
+	}
+}
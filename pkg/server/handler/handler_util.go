@@ -1,14 +1,49 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/output"
+	"chart-viewer/pkg/report"
 )
 
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
 }
 
+func respondWithServiceError(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, model.ErrUnknownKubeVersion) || errors.Is(err, model.ErrInvalidPolicy) ||
+		errors.Is(err, model.ErrInvalidVersion) || errors.Is(err, model.ErrInvalidSnapshot) ||
+		errors.Is(err, model.ErrInvalidPostRender) || errors.Is(err, model.ErrInvalidBatch) ||
+		errors.Is(err, model.ErrInvalidShowOnly) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err.Error()))
+		return
+	}
+
+	if errors.Is(err, model.ErrPolicyNotFound) || errors.Is(err, model.ErrChartNotFound) ||
+		errors.Is(err, model.ErrSnapshotNotFound) || errors.Is(err, model.ErrRenderNotFound) {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("%s: %s", message, err.Error()))
+		return
+	}
+
+	var renderErr *model.RenderError
+	if errors.As(err, &renderErr) {
+		respondWithJSON(w, http.StatusUnprocessableEntity, model.RenderError{
+			Message:     fmt.Sprintf("%s: %s", message, renderErr.Error()),
+			Diagnostics: renderErr.Diagnostics,
+		})
+		return
+	}
+
+	respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("%s: %s", message, err.Error()))
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 
@@ -22,3 +57,114 @@ func respondWithText(w http.ResponseWriter, code int, payload string) {
 	w.WriteHeader(code)
 	w.Write([]byte(payload))
 }
+
+// reportFormat reads the format query parameter of the analysis endpoints,
+// which default to JSON.
+func reportFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return report.FormatJSON, nil
+	}
+
+	if !report.IsFormat(format) {
+		return "", fmt.Errorf("unsupported format %q, use json, sarif or junit", format)
+	}
+
+	return format, nil
+}
+
+func respondWithReport(w http.ResponseWriter, format, name string, checks []report.Check) {
+	var response bytes.Buffer
+	err := report.Write(&response, format, name, checks)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("cannot write %s report: %s", format, err.Error()))
+		return
+	}
+
+	contentType := "application/sarif+json"
+	if format == report.FormatJUnit {
+		contentType = "application/xml"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(response.Bytes())
+}
+
+// acceptFormats maps the media types of the Accept header to the manifest
+// formats they ask for.
+var acceptFormats = map[string]string{
+	"application/json":   output.FormatJSON,
+	"application/yaml":   output.FormatYAML,
+	"application/x-yaml": output.FormatYAML,
+	"text/yaml":          output.FormatYAML,
+	"text/x-yaml":        output.FormatYAML,
+	"text/plain":         output.FormatYAML,
+	"application/gzip":   output.FormatTar,
+	"application/x-gzip": output.FormatTar,
+	"application/x-tar":  output.FormatTar,
+}
+
+// manifestFormat reads the output format of the manifests endpoints from the
+// format query parameter, or else from the first media type of the Accept
+// header that maps to a format.
+func manifestFormat(r *http.Request, defaultFormat string, formats ...string) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+			mediaType = strings.TrimSpace(strings.Split(mediaType, ";")[0])
+			if accepted, ok := acceptFormats[mediaType]; ok && contains(formats, accepted) {
+				return accepted, nil
+			}
+		}
+
+		return defaultFormat, nil
+	}
+
+	if !contains(formats, format) {
+		return "", fmt.Errorf("unsupported format %q, use %s", format, strings.Join(formats, ", "))
+	}
+
+	return format, nil
+}
+
+// storedManifestFormat reads the output format of the endpoints that return
+// stored manifests, which default to the YAML stream and return JSON as a
+// Kubernetes List.
+func storedManifestFormat(r *http.Request) (string, error) {
+	format, err := manifestFormat(r, output.FormatYAML, output.FormatYAML, output.FormatJSON, output.FormatList, output.FormatTar, output.FormatKustomize)
+	if format == output.FormatJSON {
+		format = output.FormatList
+	}
+
+	return format, err
+}
+
+// respondWithManifests writes the manifests in the given format. Archives are
+// sent as attachments named after the render.
+func respondWithManifests(w http.ResponseWriter, format, name string, manifests []model.Manifest) {
+	var response bytes.Buffer
+	err := output.Write(&response, format, manifests)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("cannot write manifests as %s: %s", format, err.Error()))
+		return
+	}
+
+	if output.IsArchive(format) {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"-"+format+".tar.gz"))
+	}
+
+	w.Header().Set("Content-Type", output.ContentType(format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(response.Bytes())
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
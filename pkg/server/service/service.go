@@ -2,33 +2,73 @@ package service
 
 import (
 	"bytes"
-	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"net/http"
-	"io"
-	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/kubeversion"
 	"chart-viewer/pkg/model"
+	"github.com/Masterminds/semver/v3"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const (
+	// changesAnnotation is the Chart.yaml annotation Artifact Hub reads the
+	// changes of a chart version from.
+	changesAnnotation = "artifacthub.io/changes"
+	// changelogTTL bounds how long a changelog is served from the cache, so
+	// versions published to the index show up without a restart.
+	changelogTTL = 10 * time.Minute
+	// snapshotsKey is the hash of snapshot summaries by ID. It replaces the
+	// JSON list once stored under "snapshots", which redis cannot read as a
+	// hash and seed migrates.
+	snapshotsKey = "snapshot-index"
+
+	// batchRenderWorkers bounds the renders a batch runs at the same time.
+	batchRenderWorkers = 8
+	maxBatchReleases   = 100
 )
 
 type Repository interface {
 	Set(string, string) error
+	SetWithExpiration(string, string, time.Duration) error
 	Get(string) (string, error)
+	Delete(string) error
+	Exists(string) (bool, error)
+	HSet(string, string, string) error
+	HGetAll(string) (map[string]string, error)
+	HDel(string, string) error
 }
 
 type Helm interface {
 	GetValues(chartUrl, chartName, chartVersion string) (map[string]interface{}, error)
 	GetTemplates(chartUrl, chartName, chartVersion string) ([]model.Template, error)
-	RenderManifest(chartUrl, chartName, chartVersion string, valuesFileLocation string) ([]model.Manifest, error)
+	RenderManifest(chartUrl, chartName, chartVersion, releaseName string, values map[string]interface{}, options model.RenderOptions, kubeAPIVersion *model.KubernetesAPIVersion) (model.RenderResult, error)
 }
 
 type Analytic interface {
-	Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error)
+	Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion, deprecations []model.APIDeprecation) ([]model.AnalyticsResult, error)
+	AnalyzeManifests(manifests []model.Manifest, kubeAPIVersions model.KubernetesAPIVersion, deprecations []model.APIDeprecation) ([]model.ManifestAnalyticsResult, error)
+	Check(manifests []model.Manifest, policies []model.Policy) ([]model.RuleFinding, error)
+	CompilePolicy(policy model.Policy) error
+	Images(manifests []model.Manifest) ([]model.ContainerImage, error)
+	CompareImages(from, to []model.ContainerImage) model.ImageComparison
+}
+
+type Validator interface {
+	Validate(manifests []model.Manifest, crds []model.Manifest, kubeVersion string) ([]model.ManifestValidationResult, error)
 }
 
 type HTTPClient interface {
@@ -40,14 +80,16 @@ type service struct {
 	repository Repository
 	analyzer   Analytic
 	httpClient HTTPClient
+	validator  Validator
 }
 
-func NewService(helmClient Helm, repository Repository, analyzer Analytic, httpClient HTTPClient) service {
+func NewService(helmClient Helm, repository Repository, analyzer Analytic, httpClient HTTPClient, validator Validator) service {
 	return service{
 		helmClient: helmClient,
 		repository: repository,
 		analyzer:   analyzer,
 		httpClient: httpClient,
+		validator:  validator,
 	}
 }
 
@@ -56,7 +98,12 @@ func (s service) GetRepos() ([]model.Repo, error) {
 	if err != nil {
 		return nil, err
 	}
+
 	var repos []model.Repo
+	if stringifiedRepos == "" {
+		return repos, nil
+	}
+
 	err = json.Unmarshal([]byte(stringifiedRepos), &repos)
 	return repos, err
 }
@@ -68,32 +115,18 @@ func (s service) GetCharts(repoName string) ([]model.Chart, error) {
 	}
 
 	var cachedCharts []model.Chart
-	err = json.Unmarshal([]byte(stringifiedCharts), &cachedCharts)
-	if err != nil {
-		return nil, err
+	if stringifiedCharts != "" {
+		err = json.Unmarshal([]byte(stringifiedCharts), &cachedCharts)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if len(cachedCharts) != 0 {
 		return cachedCharts, nil
 	}
 
-	url, err := s.getUrl(repoName)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := s.httpClient.Get(url + "/index.yaml")
-	if err != nil {
-		return nil, err
-	}
-
-	content, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	repoDetail := new(model.RepoDetailResponse)
-	err = yaml.Unmarshal(content, &repoDetail)
+	repoDetail, err := s.getRepoDetail(repoName)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +153,103 @@ func (s service) GetCharts(repoName string) ([]model.Chart, error) {
 	return charts, nil
 }
 
+// GetChangelog lists the versions of a chart between two versions, both
+// included, newest first. An empty version leaves that end of the range open.
+func (s service) GetChangelog(repoName, chartName, fromVersion, toVersion string) (model.Changelog, error) {
+	from, err := parseVersionBound(fromVersion)
+	if err != nil {
+		return model.Changelog{}, err
+	}
+
+	to, err := parseVersionBound(toVersion)
+	if err != nil {
+		return model.Changelog{}, err
+	}
+
+	entries, err := s.getChangelogEntries(repoName, chartName)
+	if err != nil {
+		return model.Changelog{}, err
+	}
+
+	changelog := model.Changelog{
+		Repo:     repoName,
+		Chart:    chartName,
+		From:     fromVersion,
+		To:       toVersion,
+		Versions: []model.ChangelogEntry{},
+	}
+
+	for _, entry := range entries {
+		version, err := semver.NewVersion(entry.Version)
+		if err != nil {
+			if from == nil && to == nil {
+				changelog.Versions = append(changelog.Versions, entry)
+			}
+			continue
+		}
+
+		if (from == nil || !version.LessThan(from)) && (to == nil || !version.GreaterThan(to)) {
+			changelog.Versions = append(changelog.Versions, entry)
+		}
+	}
+
+	return changelog, nil
+}
+
+func (s service) getChangelogEntries(repoName, chartName string) ([]model.ChangelogEntry, error) {
+	cacheKey := fmt.Sprintf("changelog-%s-%s", repoName, chartName)
+	stringifiedEntries, err := s.repository.Get(cacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var cachedEntries []model.ChangelogEntry
+	_ = json.Unmarshal([]byte(stringifiedEntries), &cachedEntries)
+	if len(cachedEntries) != 0 {
+		return cachedEntries, nil
+	}
+
+	repoDetail, err := s.getRepoDetail(repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, ok := repoDetail.Entries[chartName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", model.ErrChartNotFound, repoName, chartName)
+	}
+
+	var entries []model.ChangelogEntry
+	for _, v := range versions {
+		entry := model.ChangelogEntry{
+			Version:    v.Version,
+			AppVersion: v.AppVersion,
+			Created:    v.Created,
+		}
+
+		if annotation, ok := v.Annotations[changesAnnotation]; ok {
+			entry.Changes, err = parseChanges(annotation)
+			if err != nil {
+				log.Printf("cannot parse the changes of %s/%s:%s: %s\n", repoName, chartName, v.Version, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return compareVersions(entries[i].Version, entries[j].Version) > 0
+	})
+
+	entriesByte, _ := json.Marshal(entries)
+	err = s.repository.SetWithExpiration(cacheKey, string(entriesByte), changelogTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 func (s service) GetValues(repoName, chartName, chartVersion string) (map[string]interface{}, error) {
 	cacheKey := fmt.Sprintf("value-%s-%s-%s", repoName, chartName, chartVersion)
 	stringifiedValues, err := s.repository.Get(cacheKey)
@@ -128,9 +258,11 @@ func (s service) GetValues(repoName, chartName, chartVersion string) (map[string
 	}
 
 	var cachedValues map[string]interface{}
-	err = json.Unmarshal([]byte(stringifiedValues), &cachedValues)
-	if err != nil {
-		return nil, err
+	if stringifiedValues != "" {
+		err = json.Unmarshal([]byte(stringifiedValues), &cachedValues)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if len(cachedValues) != 0 {
@@ -211,15 +343,41 @@ func (s service) GetTemplates(repoName, chartName, chartVersion string) ([]model
 	return templates, nil
 }
 
-func (s service) RenderManifest(repoName, chartName, chartVersion string, values string) (model.ManifestResponse, error) {
-	valueBytes := []byte(values)
-	valuesFileLocation := fmt.Sprintf("/tmp/chart-viewer/%s-values.yaml", time.Now().Format("20060102150405"))
-	err := os.WriteFile(valuesFileLocation, valueBytes, 0644)
+func (s service) RenderManifest(repoName, chartName, chartVersion, releaseName string, values string, options model.RenderOptions) (model.ManifestResponse, error) {
+	return s.renderManifest(repoName, chartName, chartVersion, releaseName, values, options, nil)
+}
+
+// renderManifest renders for the given Kubernetes release, so the templates
+// that check .Capabilities take the branch that cluster would, or with the
+// Helm defaults when it is nil.
+func (s service) renderManifest(repoName, chartName, chartVersion, releaseName string, values string, options model.RenderOptions, kubeAPIVersion *model.KubernetesAPIVersion) (model.ManifestResponse, error) {
+	vals, err := chartutil.ReadValues([]byte(values))
+	if err != nil {
+		return model.ManifestResponse{}, fmt.Errorf("cannot parse values: %w", err)
+	}
+
+	inputs := renderInputs{
+		Repo:    repoName,
+		Chart:   chartName,
+		Version: chartVersion,
+		Values:  vals,
+		Options: options,
+	}
+	// Renders under the default release name, the chart name, keep the hash
+	// they had before the release name could be set.
+	if releaseName != chartName {
+		inputs.Release = releaseName
+	}
+	// Renders for a cluster see other capabilities than the default ones.
+	if kubeAPIVersion != nil {
+		inputs.KubeVersion = kubeAPIVersion.KubeVersion
+	}
+
+	hash, err := hashRenderInputs(inputs)
 	if err != nil {
 		return model.ManifestResponse{}, err
 	}
 
-	hash := hashFileContent(valuesFileLocation)
 	cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", repoName, chartName, chartVersion, hash)
 	stringifiedManifest, err := s.repository.Get(cacheKey)
 	if err != nil {
@@ -242,13 +400,17 @@ func (s service) RenderManifest(repoName, chartName, chartVersion string, values
 
 	var url string
 	repos, err := s.GetRepos()
+	if err != nil {
+		return model.ManifestResponse{}, err
+	}
+
 	for _, r := range repos {
 		if r.Name == repoName {
 			url = r.URL
 		}
 	}
 
-	manifests, err := s.helmClient.RenderManifest(url, chartName, chartVersion, valuesFileLocation)
+	rendered, err := s.helmClient.RenderManifest(url, chartName, chartVersion, releaseName, vals, options, kubeAPIVersion)
 	if err != nil {
 		log.Printf("failed to render manifest: %s\n", err)
 		return model.ManifestResponse{}, err
@@ -257,10 +419,12 @@ func (s service) RenderManifest(repoName, chartName, chartVersion string, values
 	generatedUrl := fmt.Sprintf("/api/v1/charts/manifests/%s/%s/%s/%s", repoName, chartName, chartVersion, hash)
 	manifestsResponse := model.ManifestResponse{
 		URL:       generatedUrl,
-		Manifests: manifests,
+		Manifests: rendered.Manifests,
+		CRDs:      rendered.CRDs,
+		Notes:     rendered.Notes,
 	}
 
-	manifestsByte, err := json.Marshal(manifestsResponse)
+	manifestsByte, err := json.Marshal(renderCache{ManifestResponse: manifestsResponse, Values: rendered.Values})
 	if err != nil {
 		log.Printf("failed to marshal manifest: %s\n", err)
 		return model.ManifestResponse{}, err
@@ -270,16 +434,274 @@ func (s service) RenderManifest(repoName, chartName, chartVersion string, values
 	return manifestsResponse, err
 }
 
-func (s service) GetStringifiedManifests(repoName, chartName, chartVersion, hash string) (string, error) {
+// RenderBatch renders the releases concurrently and combines their manifests,
+// reporting the objects rendered by more than one release. A release that
+// fails to render or to parse is reported with its error and left out of the
+// manifests.
+func (s service) RenderBatch(releases []model.BatchRelease) (model.BatchRender, error) {
+	if len(releases) == 0 {
+		return model.BatchRender{}, fmt.Errorf("%w: at least one release is required", model.ErrInvalidBatch)
+	}
+	if len(releases) > maxBatchReleases {
+		return model.BatchRender{}, fmt.Errorf("%w: at most %d releases can be rendered at once", model.ErrInvalidBatch, maxBatchReleases)
+	}
+
+	releases = append([]model.BatchRelease(nil), releases...)
+	names := map[string]bool{}
+	for i := range releases {
+		if releases[i].Name == "" {
+			releases[i].Name = releases[i].Chart
+		}
+		if names[releases[i].Name] {
+			return model.BatchRender{}, fmt.Errorf("%w: release %q is defined twice", model.ErrInvalidBatch, releases[i].Name)
+		}
+		names[releases[i].Name] = true
+	}
+
+	results := make([]model.BatchReleaseResult, len(releases))
+	rendered := make([]model.ManifestResponse, len(releases))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < batchRenderWorkers && w < len(releases); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rendered[i], results[i] = s.renderRelease(releases[i])
+			}
+		}()
+	}
+
+	for i := range releases {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var manifests []model.ReleaseManifest
+	for i, result := range results {
+		if result.Error != "" {
+			continue
+		}
+
+		for _, m := range append(rendered[i].CRDs, rendered[i].Manifests...) {
+			manifests = append(manifests, model.ReleaseManifest{Release: result.Name, Manifest: m})
+		}
+	}
+
+	// A release whose manifests cannot be parsed fails on its own, the
+	// duplicates of the other releases are still reported.
+	duplicates, parseErrs := diff.Duplicates(manifests)
+	for i := range results {
+		if err, ok := parseErrs[results[i].Name]; ok {
+			results[i].Error = fmt.Sprintf("cannot check duplicates: %s", err)
+		}
+	}
+
+	batch := model.BatchRender{Releases: results, Manifests: []model.ReleaseManifest{}, Duplicates: duplicates}
+	for _, result := range results {
+		if result.Error != "" {
+			batch.Failed++
+			continue
+		}
+		batch.Succeeded++
+	}
+	for _, m := range manifests {
+		if _, failed := parseErrs[m.Release]; !failed {
+			batch.Manifests = append(batch.Manifests, m)
+		}
+	}
+
+	return batch, nil
+}
+
+func (s service) renderRelease(release model.BatchRelease) (model.ManifestResponse, model.BatchReleaseResult) {
+	result := model.BatchReleaseResult{
+		Name:    release.Name,
+		Repo:    release.Repo,
+		Chart:   release.Chart,
+		Version: release.Version,
+	}
+
+	if release.Repo == "" || release.Chart == "" || release.Version == "" {
+		result.Error = "the repo, chart and version are required"
+		return model.ManifestResponse{}, result
+	}
+
+	rendered, err := s.RenderManifest(release.Repo, release.Chart, release.Version, release.Name, release.Values, release.Options)
+	if err != nil {
+		result.Error = err.Error()
+
+		var renderErr *model.RenderError
+		if errors.As(err, &renderErr) {
+			result.Diagnostics = renderErr.Diagnostics
+		}
+
+		return model.ManifestResponse{}, result
+	}
+
+	result.URL = rendered.URL
+	return rendered, result
+}
+
+func (s service) DiffValues(repoName, chartName, fromVersion, toVersion string) (model.ValuesDiff, error) {
+	fromValues, err := s.GetValues(repoName, chartName, fromVersion)
+	if err != nil {
+		return model.ValuesDiff{}, err
+	}
+
+	toValues, err := s.GetValues(repoName, chartName, toVersion)
+	if err != nil {
+		return model.ValuesDiff{}, err
+	}
+
+	result := diff.Values(fromValues, toValues)
+	result.Repo = repoName
+	result.Chart = chartName
+	result.From = fromVersion
+	result.To = toVersion
+
+	return result, nil
+}
+
+func (s service) DiffTemplates(repoName, chartName, fromVersion, toVersion string) (model.TemplatesDiff, error) {
+	fromTemplates, err := s.GetTemplates(repoName, chartName, fromVersion)
+	if err != nil {
+		return model.TemplatesDiff{}, err
+	}
+
+	toTemplates, err := s.GetTemplates(repoName, chartName, toVersion)
+	if err != nil {
+		return model.TemplatesDiff{}, err
+	}
+
+	result := diff.Templates(fromTemplates, toTemplates)
+	result.Repo = repoName
+	result.Chart = chartName
+	result.From = fromVersion
+	result.To = toVersion
+
+	return result, nil
+}
+
+func (s service) DiffManifests(repoName, chartName string, from, to model.RenderSpec) (model.ManifestDiff, error) {
+	if to.Version == "" {
+		to.Version = from.Version
+	}
+
+	fromRender, err := s.RenderManifest(repoName, chartName, from.Version, chartName, from.Values, from.Options)
+	if err != nil {
+		return model.ManifestDiff{}, err
+	}
+
+	toRender, err := s.RenderManifest(repoName, chartName, to.Version, chartName, to.Values, to.Options)
+	if err != nil {
+		return model.ManifestDiff{}, err
+	}
+
+	result, err := diff.Manifests(fromRender.Manifests, toRender.Manifests)
+	if err != nil {
+		return model.ManifestDiff{}, err
+	}
+
+	result.Repo = repoName
+	result.Chart = chartName
+	result.From = from.Version
+	result.To = to.Version
+
+	return result, nil
+}
+
+func (s service) GetUpgradeImpact(repoName, chartName, fromVersion, toVersion string, values string, options model.RenderOptions) (model.UpgradeImpact, error) {
+	userValues, err := chartutil.ReadValues([]byte(values))
+	if err != nil {
+		return model.UpgradeImpact{}, fmt.Errorf("cannot parse values: %w", err)
+	}
+
+	valuesDiff, err := s.DiffValues(repoName, chartName, fromVersion, toVersion)
+	if err != nil {
+		return model.UpgradeImpact{}, err
+	}
+
+	fromRender, err := s.RenderManifest(repoName, chartName, fromVersion, chartName, values, options)
+	if err != nil {
+		return model.UpgradeImpact{}, err
+	}
+
+	toRender, err := s.RenderManifest(repoName, chartName, toVersion, chartName, values, options)
+	if err != nil {
+		return model.UpgradeImpact{}, err
+	}
+
+	manifestDiff, err := diff.UpgradeManifests(fromRender.Manifests, toRender.Manifests)
+	if err != nil {
+		return model.UpgradeImpact{}, err
+	}
+
+	images, err := s.compareImages(fromRender.Manifests, toRender.Manifests)
+	if err != nil {
+		return model.UpgradeImpact{}, err
+	}
+
+	result := diff.Impact(valuesDiff, userValues.AsMap(), manifestDiff, images)
+	result.Repo = repoName
+	result.Chart = chartName
+	result.From = fromVersion
+	result.To = toVersion
+
+	return result, nil
+}
+
+func (s service) GetStringifiedManifests(repoName, chartName, chartVersion, hash string, withNotes bool) (string, error) {
+	cachedManifests, err := s.GetRenderedManifests(repoName, chartName, chartVersion, hash)
+	if err != nil {
+		return "", err
+	}
+
+	stringifiedManifests := stringfyManifest(append(cachedManifests.CRDs, cachedManifests.Manifests...))
+	if withNotes && cachedManifests.Notes != "" {
+		stringifiedManifests += stringfyNotes(cachedManifests.Notes)
+	}
+
+	return stringifiedManifests, nil
+}
+
+// GetRenderedManifests returns a stored render by the hash of its inputs.
+func (s service) GetRenderedManifests(repoName, chartName, chartVersion, hash string) (model.ManifestResponse, error) {
+	cachedManifests, err := s.getRenderCache(repoName, chartName, chartVersion, hash)
+	return cachedManifests.ManifestResponse, err
+}
+
+func (s service) getRenderCache(repoName, chartName, chartVersion, hash string) (renderCache, error) {
 	cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", repoName, chartName, chartVersion, hash)
-	var cachedManifests model.ManifestResponse
 	stringifiedManifest, err := s.repository.Get(cacheKey)
 	if err != nil {
-		return "", err
+		return renderCache{}, err
 	}
 
+	if stringifiedManifest == "" {
+		return renderCache{}, fmt.Errorf("%w: %s", model.ErrRenderNotFound, hash)
+	}
+
+	var cachedManifests renderCache
 	err = json.Unmarshal([]byte(stringifiedManifest), &cachedManifests)
-	return stringfyManifest(cachedManifests.Manifests), err
+	return cachedManifests, err
+}
+
+// GetRenderValues returns the values a stored render was made with, the chart
+// defaults merged with the values of the request.
+func (s service) GetRenderValues(repoName, chartName, chartVersion, hash string) (map[string]interface{}, error) {
+	cachedManifests, err := s.getRenderCache(repoName, chartName, chartVersion, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if cachedManifests.Values == nil {
+		return nil, fmt.Errorf("%w: the values of %s were not stored, render it again", model.ErrRenderNotFound, hash)
+	}
+
+	return cachedManifests.Values, nil
 }
 
 func (s service) GetChart(repoName string, chartName string, chartVersion string) (model.ChartDetail, error) {
@@ -296,49 +718,771 @@ func (s service) GetChart(repoName string, chartName string, chartVersion string
 }
 
 func (s service) AnalyzeTemplate(templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error) {
-	stringifiedApiVersion, err := s.repository.Get("api-versions")
+	kubeAPIVersion, err := s.getKubeAPIVersion(kubeVersion)
 	if err != nil {
 		return nil, err
 	}
-	var kubeAPIVersions []model.KubernetesAPIVersion
-	err = json.Unmarshal([]byte(stringifiedApiVersion), &kubeAPIVersions)
+
+	deprecations, err := s.getAPIDeprecations()
 	if err != nil {
 		return nil, err
 	}
 
-	var kubeAPIVersion model.KubernetesAPIVersion
-	for _, k := range kubeAPIVersions {
-		if k.KubeVersion == kubeVersion {
-			kubeAPIVersion = k
-		}
+	return s.analyzer.Analyze(templates, kubeAPIVersion, deprecations)
+}
+
+func (s service) AnalyzeManifest(repoName, chartName, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestAnalyticsResult, error) {
+	kubeAPIVersion, err := s.getKubeAPIVersion(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	deprecations, err := s.getAPIDeprecations()
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := s.renderManifest(repoName, chartName, chartVersion, chartName, values, options, &kubeAPIVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.analyzer.Analyze(templates, kubeAPIVersion)
+	return s.analyzer.AnalyzeManifests(rendered.Manifests, kubeAPIVersion, deprecations)
 }
 
-func hashFileContent(fileLocation string) string {
-	valuesFileContent, _ := os.ReadFile(fileLocation)
-	hash := md5.Sum(valuesFileContent)
-	return fmt.Sprintf("%x", hash)
+func (s service) ValidateManifest(repoName, chartName, chartVersion string, values string, options model.RenderOptions, kubeVersion string) ([]model.ManifestValidationResult, error) {
+	kubeAPIVersion, err := s.getKubeAPIVersion(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := s.renderManifest(repoName, chartName, chartVersion, chartName, values, options, &kubeAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.validator.Validate(rendered.Manifests, rendered.CRDs, kubeAPIVersion.KubeVersion)
 }
 
-func getVersion(name string, entries map[string][]model.ChartResponse) []string {
-	cs := entries[name]
+func (s service) GetChartReport(repoName, chartName, chartVersion string, values string, options model.RenderOptions) (model.ChartReport, error) {
+	rendered, err := s.RenderManifest(repoName, chartName, chartVersion, chartName, values, options)
+	if err != nil {
+		return model.ChartReport{}, err
+	}
 
-	var versions []string
+	policies, err := s.GetPolicies()
+	if err != nil {
+		return model.ChartReport{}, err
+	}
 
-	for _, c := range cs {
-		versions = append(versions, c.Version)
+	findings, err := s.analyzer.Check(rendered.Manifests, policies)
+	if err != nil {
+		return model.ChartReport{}, err
 	}
 
-	return versions
+	report := model.ChartReport{
+		Repo:    repoName,
+		Chart:   chartName,
+		Version: chartVersion,
+		Summary: model.ReportSummary{
+			Total:      len(findings),
+			BySeverity: map[string]int{},
+			ByRule:     map[string]int{},
+		},
+		Findings: []model.RuleFinding{},
+	}
+
+	for _, f := range findings {
+		report.Summary.BySeverity[f.Severity]++
+		report.Summary.ByRule[f.RuleID]++
+		report.Findings = append(report.Findings, f)
+	}
+
+	return report, nil
 }
 
-func stringfyManifest(manifests []model.Manifest) string {
-	var buffer bytes.Buffer
-	var delimiter = "---\n"
-	for _, m := range manifests {
-		buffer.WriteString(delimiter + m.Content + "\n")
+func (s service) GetImageInventory(repoName, chartName, chartVersion string, values string, options model.RenderOptions) (model.ImageInventory, error) {
+	images, err := s.getImages(repoName, chartName, chartVersion, values, options)
+	if err != nil {
+		return model.ImageInventory{}, err
+	}
+
+	return model.ImageInventory{
+		Repo:    repoName,
+		Chart:   chartName,
+		Version: chartVersion,
+		Images:  images,
+	}, nil
+}
+
+func (s service) CompareImages(repoName, chartName, fromVersion, toVersion string, values string, options model.RenderOptions) (model.ImageComparison, error) {
+	fromRender, err := s.RenderManifest(repoName, chartName, fromVersion, chartName, values, options)
+	if err != nil {
+		return model.ImageComparison{}, err
+	}
+
+	toRender, err := s.RenderManifest(repoName, chartName, toVersion, chartName, values, options)
+	if err != nil {
+		return model.ImageComparison{}, err
+	}
+
+	comparison, err := s.compareImages(fromRender.Manifests, toRender.Manifests)
+	if err != nil {
+		return model.ImageComparison{}, err
+	}
+
+	comparison.Repo = repoName
+	comparison.Chart = chartName
+	comparison.From = fromVersion
+	comparison.To = toVersion
+
+	return comparison, nil
+}
+
+// compareImages compares the images of two renders.
+func (s service) compareImages(from, to []model.Manifest) (model.ImageComparison, error) {
+	fromImages, err := s.images(from)
+	if err != nil {
+		return model.ImageComparison{}, err
+	}
+
+	toImages, err := s.images(to)
+	if err != nil {
+		return model.ImageComparison{}, err
+	}
+
+	return s.analyzer.CompareImages(fromImages, toImages), nil
+}
+
+func (s service) getImages(repoName, chartName, chartVersion string, values string, options model.RenderOptions) ([]model.ContainerImage, error) {
+	rendered, err := s.RenderManifest(repoName, chartName, chartVersion, chartName, values, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.images(rendered.Manifests)
+}
+
+func (s service) images(manifests []model.Manifest) ([]model.ContainerImage, error) {
+	images, err := s.analyzer.Images(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	if images == nil {
+		images = []model.ContainerImage{}
+	}
+
+	return images, nil
+}
+
+func (s service) GetPolicies() ([]model.Policy, error) {
+	stringifiedPolicies, err := s.repository.Get("policies")
+	if err != nil {
+		return nil, err
+	}
+
+	policies := []model.Policy{}
+	if stringifiedPolicies == "" {
+		return policies, nil
+	}
+
+	err = json.Unmarshal([]byte(stringifiedPolicies), &policies)
+	return policies, err
+}
+
+func (s service) SavePolicy(policy model.Policy) error {
+	err := s.analyzer.CompilePolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	policies, err := s.GetPolicies()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, p := range policies {
+		if p.ID == policy.ID {
+			policies[i] = policy
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		policies = append(policies, policy)
+	}
+
+	return s.savePolicies(policies)
+}
+
+func (s service) DeletePolicy(id string) error {
+	policies, err := s.GetPolicies()
+	if err != nil {
+		return err
+	}
+
+	remaining := []model.Policy{}
+	for _, p := range policies {
+		if p.ID != id {
+			remaining = append(remaining, p)
+		}
+	}
+
+	if len(remaining) == len(policies) {
+		return fmt.Errorf("%w: %s", model.ErrPolicyNotFound, id)
+	}
+
+	return s.savePolicies(remaining)
+}
+
+func (s service) savePolicies(policies []model.Policy) error {
+	policiesByte, err := json.Marshal(policies)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Set("policies", string(policiesByte))
+}
+
+func (s service) CreateSnapshot(repoName, chartName, chartVersion string, req model.SnapshotRequest) (model.Snapshot, error) {
+	return s.createSnapshot(repoName, chartName, chartVersion, req, "")
+}
+
+func (s service) createSnapshot(repoName, chartName, chartVersion string, req model.SnapshotRequest, parentID string) (model.Snapshot, error) {
+	if req.Name == "" {
+		return model.Snapshot{}, fmt.Errorf("%w: the name is required", model.ErrInvalidSnapshot)
+	}
+
+	expiresIn, err := parseExpiresIn(req.ExpiresIn)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	rendered, err := s.RenderManifest(repoName, chartName, chartVersion, chartName, req.Values, req.Options)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	id, err := newSnapshotID()
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	snapshot := model.Snapshot{
+		ID:        id,
+		Name:      req.Name,
+		Author:    req.Author,
+		Repo:      repoName,
+		Chart:     chartName,
+		Version:   chartVersion,
+		Values:    req.Values,
+		Options:   req.Options,
+		ParentID:  parentID,
+		URL:       fmt.Sprintf("/api/v1/snapshots/%s", id),
+		CreatedAt: time.Now().UTC(),
+		Manifests: rendered.Manifests,
+		CRDs:      rendered.CRDs,
+		Notes:     rendered.Notes,
+	}
+
+	if expiresIn != 0 {
+		expiresAt := snapshot.CreatedAt.Add(expiresIn)
+		snapshot.ExpiresAt = &expiresAt
+	}
+
+	snapshotByte, err := json.Marshal(snapshot)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	err = s.repository.SetWithExpiration(snapshotKey(id), string(snapshotByte), expiresIn)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	summaryByte, err := json.Marshal(summary(snapshot))
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	err = s.repository.HSet(snapshotsKey, id, string(summaryByte))
+	return snapshot, err
+}
+
+// RerenderSnapshot renders the values and options of a snapshot against
+// another chart version and saves the result as a new snapshot.
+func (s service) RerenderSnapshot(id string, req model.SnapshotRerenderRequest) (model.Snapshot, error) {
+	parent, err := s.GetSnapshot(id)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	snapshotRequest := model.SnapshotRequest{
+		Name:      req.Name,
+		Author:    req.Author,
+		Values:    parent.Values,
+		Options:   parent.Options,
+		ExpiresIn: req.ExpiresIn,
+	}
+	if snapshotRequest.Name == "" {
+		snapshotRequest.Name = parent.Name
+	}
+
+	version := req.Version
+	if version == "" {
+		version = parent.Version
+	}
+
+	return s.createSnapshot(parent.Repo, parent.Chart, version, snapshotRequest, parent.ID)
+}
+
+// GetSnapshots lists the snapshots without their render output, newest first.
+// The list is a hash of summaries by ID, so concurrent changes do not
+// overwrite each other. Snapshots whose key is gone, expired or deleted, are
+// removed from it, and a summary that cannot be parsed is skipped.
+func (s service) GetSnapshots() ([]model.Snapshot, error) {
+	stringifiedSnapshots, err := s.repository.HGetAll(snapshotsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	snapshots := []model.Snapshot{}
+	for id, stringifiedSnapshot := range stringifiedSnapshots {
+		var snapshot model.Snapshot
+		err = json.Unmarshal([]byte(stringifiedSnapshot), &snapshot)
+		if err != nil {
+			log.Printf("skipping snapshot %s, cannot parse its summary: %s\n", id, err)
+			continue
+		}
+
+		exists, err := s.repository.Exists(snapshotKey(id))
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists || expired(snapshot, now) {
+			err = s.repository.HDel(snapshotsKey, id)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+		}
+		return snapshots[i].ID < snapshots[j].ID
+	})
+
+	return snapshots, nil
+}
+
+func (s service) GetSnapshot(id string) (model.Snapshot, error) {
+	stringifiedSnapshot, err := s.repository.Get(snapshotKey(id))
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	if stringifiedSnapshot == "" {
+		return model.Snapshot{}, fmt.Errorf("%w: %s", model.ErrSnapshotNotFound, id)
+	}
+
+	var snapshot model.Snapshot
+	err = json.Unmarshal([]byte(stringifiedSnapshot), &snapshot)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	if expired(snapshot, time.Now()) {
+		return model.Snapshot{}, fmt.Errorf("%w: %s", model.ErrSnapshotNotFound, id)
+	}
+
+	return snapshot, nil
+}
+
+func (s service) GetStringifiedSnapshot(id string, withNotes bool) (string, error) {
+	snapshot, err := s.GetSnapshot(id)
+	if err != nil {
+		return "", err
+	}
+
+	stringifiedManifests := stringfyManifest(append(snapshot.CRDs, snapshot.Manifests...))
+	if withNotes && snapshot.Notes != "" {
+		stringifiedManifests += stringfyNotes(snapshot.Notes)
+	}
+
+	return stringifiedManifests, nil
+}
+
+func (s service) DeleteSnapshot(id string) error {
+	exists, err := s.repository.Exists(snapshotKey(id))
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		err = s.repository.Delete(snapshotKey(id))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.repository.HDel(snapshotsKey, id)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %s", model.ErrSnapshotNotFound, id)
+	}
+
+	return nil
+}
+
+func (s service) GetCompatibilityMatrix(repoName, chartName string) (model.CompatibilityMatrix, error) {
+	charts, err := s.GetCharts(repoName)
+	if err != nil {
+		return model.CompatibilityMatrix{}, err
+	}
+
+	var chartVersions []string
+	found := false
+	for _, c := range charts {
+		if c.Name == chartName {
+			chartVersions = c.Versions
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return model.CompatibilityMatrix{}, fmt.Errorf("%w: %s in %s", model.ErrChartNotFound, chartName, repoName)
+	}
+
+	kubeAPIVersions, err := s.getKubeAPIVersions()
+	if err != nil {
+		return model.CompatibilityMatrix{}, err
+	}
+
+	deprecations, err := s.getAPIDeprecations()
+	if err != nil {
+		return model.CompatibilityMatrix{}, err
+	}
+
+	matrix := model.CompatibilityMatrix{
+		Repo:         repoName,
+		Chart:        chartName,
+		KubeVersions: []string{},
+		Versions:     []model.ChartCompatibility{},
+	}
+	for _, k := range kubeAPIVersions {
+		matrix.KubeVersions = append(matrix.KubeVersions, k.KubeVersion)
+	}
+
+	for _, chartVersion := range chartVersions {
+		compatibility := model.ChartCompatibility{
+			Version:             chartVersion,
+			KubeVersions:        []string{},
+			UnknownKubeVersions: []string{},
+		}
+
+		templates, err := s.GetTemplates(repoName, chartName, chartVersion)
+		if err != nil {
+			compatibility.Error = err.Error()
+			matrix.Versions = append(matrix.Versions, compatibility)
+			continue
+		}
+
+		for _, k := range kubeAPIVersions {
+			results, err := s.analyzer.Analyze(templates, k, deprecations)
+			if err != nil {
+				return model.CompatibilityMatrix{}, err
+			}
+
+			switch {
+			case !allCompatible(results):
+			case anyUnchecked(results):
+				compatibility.UnknownKubeVersions = append(compatibility.UnknownKubeVersions, k.KubeVersion)
+			default:
+				compatibility.KubeVersions = append(compatibility.KubeVersions, k.KubeVersion)
+			}
+		}
+
+		// The range summarizes the list, which also shows the gaps in it.
+		if len(compatibility.KubeVersions) != 0 {
+			compatibility.MinKubeVersion = compatibility.KubeVersions[0]
+			compatibility.MaxKubeVersion = compatibility.KubeVersions[len(compatibility.KubeVersions)-1]
+		}
+
+		matrix.Versions = append(matrix.Versions, compatibility)
+	}
+
+	return matrix, nil
+}
+
+// getKubeAPIVersion returns the API versions of the given Kubernetes release,
+// or of the latest seeded release when no version is given.
+func (s service) getKubeAPIVersion(kubeVersion string) (model.KubernetesAPIVersion, error) {
+	kubeAPIVersions, err := s.getKubeAPIVersions()
+	if err != nil {
+		return model.KubernetesAPIVersion{}, err
+	}
+
+	return kubeversion.Find(kubeAPIVersions, kubeVersion)
+}
+
+func (s service) getKubeAPIVersions() ([]model.KubernetesAPIVersion, error) {
+	stringifiedApiVersion, err := s.repository.Get("api-versions")
+	if err != nil {
+		return nil, err
+	}
+
+	if stringifiedApiVersion == "" {
+		return nil, errors.New("the Kubernetes API versions are not seeded")
+	}
+
+	var kubeAPIVersions []model.KubernetesAPIVersion
+	err = json.Unmarshal([]byte(stringifiedApiVersion), &kubeAPIVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(kubeAPIVersions, func(i, j int) bool {
+		return kubeversion.Less(kubeAPIVersions[i].KubeVersion, kubeAPIVersions[j].KubeVersion)
+	})
+
+	return kubeAPIVersions, nil
+}
+
+func (s service) getAPIDeprecations() ([]model.APIDeprecation, error) {
+	stringifiedDeprecations, err := s.repository.Get("api-deprecations")
+	if err != nil {
+		return nil, err
+	}
+
+	// The deprecations are optional, seed goes on without them.
+	deprecations := []model.APIDeprecation{}
+	if stringifiedDeprecations == "" {
+		return deprecations, nil
+	}
+
+	err = json.Unmarshal([]byte(stringifiedDeprecations), &deprecations)
+	if err != nil {
+		return nil, err
+	}
+
+	return deprecations, nil
+}
+
+// renderCache is the stored render, which keeps the values it was made with
+// for GetRenderValues without returning them in every render response.
+type renderCache struct {
+	model.ManifestResponse
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+type renderInputs struct {
+	Repo        string                 `json:"repo"`
+	Chart       string                 `json:"chart"`
+	Version     string                 `json:"version"`
+	Release     string                 `json:"release,omitempty"`
+	KubeVersion string                 `json:"kube_version,omitempty"`
+	Values      map[string]interface{} `json:"values"`
+	Options     model.RenderOptions    `json:"options"`
+}
+
+// hashRenderInputs digests the JSON encoding of the inputs, which has sorted
+// map keys, so values that differ only in formatting or key order share a hash.
+func hashRenderInputs(inputs renderInputs) (string, error) {
+	showOnly := append([]string(nil), inputs.Options.ShowOnly...)
+	sort.Strings(showOnly)
+	inputs.Options.ShowOnly = showOnly
+
+	canonical, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(canonical)
+	return fmt.Sprintf("%x", hash), nil
+}
+
+func allCompatible(results []model.AnalyticsResult) bool {
+	for _, r := range results {
+		if !r.Compatible {
+			return false
+		}
+	}
+
+	return true
+}
+
+// anyUnchecked reports whether an apiVersion of the templates could not be
+// read, so the chart may still fail on a version it otherwise supports.
+func anyUnchecked(results []model.AnalyticsResult) bool {
+	for _, r := range results {
+		if r.Unchecked {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s service) getRepoDetail(repoName string) (*model.RepoDetailResponse, error) {
+	url, err := s.getUrl(repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.httpClient.Get(url + "/index.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	repoDetail := new(model.RepoDetailResponse)
+	err = yaml.Unmarshal(content, &repoDetail)
+	if err != nil {
+		return nil, err
+	}
+
+	return repoDetail, nil
+}
+
+// parseChanges parses the artifacthub.io/changes annotation, a YAML list of
+// either plain descriptions or objects with a kind, a description and links.
+func parseChanges(annotation string) ([]model.ChartChange, error) {
+	var items []yaml.Node
+	err := yaml.Unmarshal([]byte(annotation), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []model.ChartChange
+	for _, item := range items {
+		var change model.ChartChange
+		if item.Kind == yaml.ScalarNode {
+			change.Description = item.Value
+		} else if err := item.Decode(&change); err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func parseVersionBound(version string) (*semver.Version, error) {
+	if version == "" {
+		return nil, nil
+	}
+
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", model.ErrInvalidVersion, version)
+	}
+
+	return parsed, nil
+}
+
+// compareVersions orders semantic versions before the others, which are
+// compared as strings.
+func compareVersions(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func snapshotKey(id string) string {
+	return fmt.Sprintf("snapshot-%s", id)
+}
+
+func newSnapshotID() (string, error) {
+	id := make([]byte, 8)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// summary returns the snapshot without its render output, as kept in the list.
+func summary(snapshot model.Snapshot) model.Snapshot {
+	snapshot.Manifests = nil
+	snapshot.CRDs = nil
+	snapshot.Notes = ""
+	return snapshot
+}
+
+func expired(snapshot model.Snapshot, now time.Time) bool {
+	return snapshot.ExpiresAt != nil && !now.Before(*snapshot.ExpiresAt)
+}
+
+func parseExpiresIn(expiresIn string) (time.Duration, error) {
+	if expiresIn == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(expiresIn)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%w: expires_in must be a positive duration like 72h, got %q", model.ErrInvalidSnapshot, expiresIn)
+	}
+
+	return duration, nil
+}
+
+func getVersion(name string, entries map[string][]model.ChartResponse) []string {
+	cs := entries[name]
+
+	var versions []string
+
+	for _, c := range cs {
+		versions = append(versions, c.Version)
+	}
+
+	return versions
+}
+
+func stringfyManifest(manifests []model.Manifest) string {
+	var buffer bytes.Buffer
+	var delimiter = "---\n"
+	for _, m := range manifests {
+		buffer.WriteString(delimiter + m.Content + "\n")
+	}
+
+	return buffer.String()
+}
+
+// stringfyNotes comments out every line of the notes so the stream can still be
+// piped to kubectl apply.
+func stringfyNotes(notes string) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("---\n# NOTES:\n")
+	for _, line := range strings.Split(strings.TrimRight(notes, "\n"), "\n") {
+		buffer.WriteString(strings.TrimRight("# "+line, " ") + "\n")
 	}
 
 	return buffer.String()
@@ -352,7 +1496,7 @@ func (s service) getUrl(repoName string) (string, error) {
 
 	for _, r := range repos {
 		if r.Name == repoName {
-			return r.GetURL()
+			return r.GetURL(), nil
 		}
 	}
 
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"chart-viewer/pkg/model"
 	"chart-viewer/pkg/server/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_service_GetRepos(t *testing.T) {
@@ -58,12 +61,23 @@ func Test_service_GetRepos(t *testing.T) {
 				ff.repository.On("Get", "repos").Return("", errors.New("error"))
 			},
 		},
+		{
+			name: "should return no repositories when they are not seeded",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			want:    nil,
+			wantErr: nil,
+			mockFn: func(ff fields) {
+				ff.repository.On("Get", "repos").Return("", nil)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.fields)
 
-			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient)
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
 			actual, err := svc.GetRepos()
 			assert.Equal(t, err, tt.wantErr)
 			assert.Equal(t, actual, tt.want)
@@ -139,6 +153,49 @@ func Test_service_GetCharts(t *testing.T) {
 				url := "https://chart.stable.com/index.yaml"
 				responseBody := `apiVersion: v1
 entries:
+  acs-engine-autoscaler:
+    - version: 2.2.2`
+				mockedResponseBody := io.NopCloser(bytes.NewReader([]byte(responseBody)))
+				ff.httpClient.On("Get", url).Return(&http.Response{Body: mockedResponseBody}, nil)
+
+				charts := []model.Chart{
+					{
+						Name:     "acs-engine-autoscaler",
+						Versions: []string{"2.2.2"},
+					},
+				}
+				chartsByte, _ := json.Marshal(charts)
+				ff.repository.On("Set", "stable", string(chartsByte)).Return(nil)
+			},
+		},
+		{
+			name: "should get charts from remote server when the repository is not cached",
+			fields: fields{
+				helm:       new(mocks.Helm),
+				repository: new(mocks.Repository),
+				analyzer:   new(mocks.Analytic),
+				httpClient: new(mocks.HTTPClient),
+			},
+			args: args{repoName: "stable"},
+			want: []model.Chart{
+				{
+					Name: "acs-engine-autoscaler",
+					Versions: []string{
+						"2.2.2",
+					},
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				stringifiedChart := ""
+				ff.repository.On("Get", "stable").Return(stringifiedChart, nil)
+
+				stringifiedRepos := `[{"name":"stable","url":"https://chart.stable.com"}]`
+				ff.repository.On("Get", "repos").Return(stringifiedRepos, nil)
+
+				url := "https://chart.stable.com/index.yaml"
+				responseBody := `apiVersion: v1
+entries:
   acs-engine-autoscaler:
     - version: 2.2.2`
 				mockedResponseBody := io.NopCloser(bytes.NewReader([]byte(responseBody)))
@@ -250,7 +307,7 @@ entries:
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.fields, tt.args)
 
-			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient)
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
 			actual, err := svc.GetCharts(tt.args.repoName)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, actual)
@@ -258,6 +315,111 @@ entries:
 	}
 }
 
+func Test_service_GetChangelog(t *testing.T) {
+	repository := new(mocks.Repository)
+	httpClient := new(mocks.HTTPClient)
+
+	repository.On("Get", "changelog-stable-app").Return("", nil)
+	repository.On("Get", "repos").Return(`[{"name":"stable","url":"https://chart.stable.com"}]`, nil)
+
+	responseBody := `apiVersion: v1
+entries:
+  app:
+    - version: 1.10.0
+      appVersion: "2.1"
+      created: "2023-03-01T10:00:00Z"
+      annotations:
+        artifacthub.io/changes: |
+          - kind: fixed
+            description: Use the release namespace
+            links:
+              - name: PR
+                url: https://github.com/acme/charts/pull/12
+          - Bump the app to 2.1
+    - version: 1.9.0
+      appVersion: "2.0"
+      created: "2023-02-01T10:00:00Z"
+    - version: 1.2.0
+      appVersion: "1.0"
+      created: "2022-01-01T10:00:00Z"
+      annotations:
+        artifacthub.io/changes: "{invalid"`
+	httpClient.On("Get", "https://chart.stable.com/index.yaml").Return(&http.Response{Body: io.NopCloser(bytes.NewReader([]byte(responseBody)))}, nil).Once()
+
+	v1100 := model.ChangelogEntry{
+		Version:    "1.10.0",
+		AppVersion: "2.1",
+		Created:    "2023-03-01T10:00:00Z",
+		Changes: []model.ChartChange{
+			{Kind: "fixed", Description: "Use the release namespace", Links: []model.ChangeLink{{Name: "PR", URL: "https://github.com/acme/charts/pull/12"}}},
+			{Description: "Bump the app to 2.1"},
+		},
+	}
+	v190 := model.ChangelogEntry{Version: "1.9.0", AppVersion: "2.0", Created: "2023-02-01T10:00:00Z"}
+	v120 := model.ChangelogEntry{Version: "1.2.0", AppVersion: "1.0", Created: "2022-01-01T10:00:00Z"}
+	entriesByte, _ := json.Marshal([]model.ChangelogEntry{v1100, v190, v120})
+	repository.On("SetWithExpiration", "changelog-stable-app", string(entriesByte), 10*time.Minute).Return(nil)
+
+	svc := service.NewService(nil, repository, nil, httpClient, nil)
+	actual, err := svc.GetChangelog("stable", "app", "1.9.0", "")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Changelog{
+		Repo:     "stable",
+		Chart:    "app",
+		From:     "1.9.0",
+		Versions: []model.ChangelogEntry{v1100, v190},
+	}, actual)
+
+	_, err = svc.GetChangelog("stable", "app", "latest", "")
+	assert.ErrorIs(t, err, model.ErrInvalidVersion)
+
+	repository.On("Get", "changelog-stable-unknown").Return("", nil)
+	httpClient.On("Get", "https://chart.stable.com/index.yaml").Return(&http.Response{Body: io.NopCloser(bytes.NewReader([]byte(responseBody)))}, nil)
+	_, err = svc.GetChangelog("stable", "unknown", "", "")
+	assert.ErrorIs(t, err, model.ErrChartNotFound)
+}
+
+func Test_service_GetChangelog_newVersion(t *testing.T) {
+	repository := new(mocks.Repository)
+	httpClient := new(mocks.HTTPClient)
+
+	repository.On("Get", "repos").Return(`[{"name":"stable","url":"https://chart.stable.com"}]`, nil)
+
+	v100 := model.ChangelogEntry{Version: "1.0.0", Created: "2023-01-01T10:00:00Z"}
+	v110 := model.ChangelogEntry{Version: "1.1.0", Created: "2023-02-01T10:00:00Z"}
+	oldEntries, _ := json.Marshal([]model.ChangelogEntry{v100})
+	newEntries, _ := json.Marshal([]model.ChangelogEntry{v110, v100})
+
+	// The changelog is cached until it expires, then read from the index
+	// again, which has gained a version since.
+	repository.On("Get", "changelog-stable-app").Return("", nil).Once()
+	repository.On("Get", "changelog-stable-app").Return(string(oldEntries), nil).Once()
+	repository.On("Get", "changelog-stable-app").Return("", nil).Once()
+	repository.On("SetWithExpiration", "changelog-stable-app", string(oldEntries), 10*time.Minute).Return(nil).Once()
+	repository.On("SetWithExpiration", "changelog-stable-app", string(newEntries), 10*time.Minute).Return(nil).Once()
+
+	httpClient.On("Get", "https://chart.stable.com/index.yaml").Return(&http.Response{Body: io.NopCloser(strings.NewReader(`entries:
+  app:
+    - version: 1.0.0
+      created: "2023-01-01T10:00:00Z"`))}, nil).Once()
+	httpClient.On("Get", "https://chart.stable.com/index.yaml").Return(&http.Response{Body: io.NopCloser(strings.NewReader(`entries:
+  app:
+    - version: 1.1.0
+      created: "2023-02-01T10:00:00Z"
+    - version: 1.0.0
+      created: "2023-01-01T10:00:00Z"`))}, nil).Once()
+
+	svc := service.NewService(nil, repository, nil, httpClient, nil)
+	for _, want := range [][]model.ChangelogEntry{{v100}, {v100}, {v110, v100}} {
+		actual, err := svc.GetChangelog("stable", "app", "", "")
+		assert.NoError(t, err)
+		assert.Equal(t, want, actual.Versions)
+	}
+
+	repository.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+}
+
 func Test_service_GetValues(t *testing.T) {
 	type fields struct {
 		helm       *mocks.Helm
@@ -339,6 +501,43 @@ func Test_service_GetValues(t *testing.T) {
 				ff.repository.On("Set", cacheKey, string(chartsValues)).Return(nil)
 			},
 		},
+		{
+			name: "should get values from remote when the key does not exist",
+			fields: fields{
+				helm:       new(mocks.Helm),
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "repo",
+				chartName:    "chart",
+				chartVersion: "v0.0.1",
+			},
+			want: map[string]interface{}{
+				"ingress": map[string]interface{}{
+					"enabled": false,
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				cacheKey := fmt.Sprintf("value-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion)
+
+				stringifiedValues := ""
+				ff.repository.On("Get", cacheKey).Return(stringifiedValues, nil)
+
+				stringifiedRepos := `[{"name":"repo","url":"https://repoName.test.com"}]`
+				ff.repository.On("Get", "repos").Return(stringifiedRepos, nil)
+
+				values := map[string]interface{}{
+					"ingress": map[string]interface{}{
+						"enabled": false,
+					},
+				}
+				ff.helm.On("GetValues", "https://repoName.test.com", aa.chartName, aa.chartVersion).Return(values, nil)
+
+				chartsValues, _ := json.Marshal(values)
+				ff.repository.On("Set", cacheKey, string(chartsValues)).Return(nil)
+			},
+		},
 		{
 			name: "should failed if repo return error when getting values from cache",
 			fields: fields{
@@ -443,7 +642,7 @@ func Test_service_GetValues(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.fields, tt.args)
 
-			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient)
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
 			actual, err := svc.GetValues(tt.args.repoName, tt.args.chartName, tt.args.chartVersion)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, actual)
@@ -640,7 +839,7 @@ func Test_service_GetTemplates(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.fields, tt.args)
 
-			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient)
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
 			actual, err := svc.GetTemplates(tt.args.repoName, tt.args.chartName, tt.args.chartVersion)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, actual)
@@ -660,6 +859,7 @@ func Test_service_RenderManifest(t *testing.T) {
 		chartName    string
 		chartVersion string
 		values       string
+		options      model.RenderOptions
 	}
 	tests := []struct {
 		name    string
@@ -682,7 +882,35 @@ func Test_service_RenderManifest(t *testing.T) {
 				values:       `{"ingress": false}`,
 			},
 			want: model.ManifestResponse{
-				URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/5b5b333fa5174d95f7c2cf0a3dca1575",
+				URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0",
+				Manifests: []model.Manifest{
+					{
+						Name:    "deployment.yaml",
+						Content: "kind: Deployment",
+					},
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, "e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0")
+				stringifiedManifest := `{"url":"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}]}`
+				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
+			},
+		},
+		{
+			name: "should use the same cache entry for values that only differ in formatting",
+			fields: fields{
+				helm:       new(mocks.Helm),
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "aap-deploy",
+				chartVersion: "v0.0.1",
+				values:       "# disable ingress\ningress:   false\n",
+			},
+			want: model.ManifestResponse{
+				URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0",
 				Manifests: []model.Manifest{
 					{
 						Name:    "deployment.yaml",
@@ -692,8 +920,37 @@ func Test_service_RenderManifest(t *testing.T) {
 			},
 			wantErr: nil,
 			mockFn: func(ff fields, aa args) {
-				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, "5b5b333fa5174d95f7c2cf0a3dca1575")
-				stringifiedManifest := `{"url":"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/5b5b333fa5174d95f7c2cf0a3dca1575","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}]}`
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, "e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0")
+				stringifiedManifest := `{"url":"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}]}`
+				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
+			},
+		},
+		{
+			name: "should use another cache entry when the render has a post render pipeline",
+			fields: fields{
+				helm:       new(mocks.Helm),
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "aap-deploy",
+				chartVersion: "v0.0.1",
+				values:       `{"ingress": false}`,
+				options:      model.RenderOptions{PostRender: &model.PostRender{Namespace: "team-a"}},
+			},
+			want: model.ManifestResponse{
+				URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/1ab233aab79f2d9dc7adfbd1a7972e5e8e54c5edd528ba27e40b08ebf1826182",
+				Manifests: []model.Manifest{
+					{
+						Name:    "deployment.yaml",
+						Content: "kind: Deployment\nmetadata:\n  namespace: team-a",
+					},
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, "1ab233aab79f2d9dc7adfbd1a7972e5e8e54c5edd528ba27e40b08ebf1826182")
+				stringifiedManifest := `{"url":"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/1ab233aab79f2d9dc7adfbd1a7972e5e8e54c5edd528ba27e40b08ebf1826182","manifests":[{"name":"deployment.yaml","content":"kind: Deployment\nmetadata:\n  namespace: team-a"}]}`
 				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
 			},
 		},
@@ -710,44 +967,40 @@ func Test_service_RenderManifest(t *testing.T) {
 				values:       `{"ingress": false}`,
 			},
 			want: model.ManifestResponse{
-				URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/5b5b333fa5174d95f7c2cf0a3dca1575",
+				URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/85b82e959e552f5de7f7b72aa7f5f5778560a1f0b6087412f4df58b75ed40347",
 				Manifests: []model.Manifest{
 					{
 						Name:    "deployment.yaml",
 						Content: "kind: Deployment",
 					},
 				},
+				Notes: "Get the application URL by running these commands",
 			},
 			wantErr: nil,
 			mockFn: func(ff fields, aa args) {
-				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, "5b5b333fa5174d95f7c2cf0a3dca1575")
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, "85b82e959e552f5de7f7b72aa7f5f5778560a1f0b6087412f4df58b75ed40347")
 				stringifiedManifest := ""
 				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
 
 				stringifiedRepos := `[{"name":"stable","url":"https://chart.stable.com"}]`
 				ff.repository.On("Get", "repos").Return(stringifiedRepos, nil)
 
-				manifests := []model.Manifest{
-					{
-						Name:    "deployment.yaml",
-						Content: "kind: Deployment",
-					},
-				}
-
-				valuesFileLocation := fmt.Sprintf("/tmp/chart-viewer/%s-values.yaml", time.Now().Format("20060102150405"))
-				ff.helm.On("RenderManifest", "https://chart.stable.com", aa.chartName, aa.chartVersion, valuesFileLocation).Return(manifests, nil)
-
-				manifestReponse := model.ManifestResponse{
-					URL: "/api/v1/charts/manifests/stable/app-deploy/v0.0.1/5b5b333fa5174d95f7c2cf0a3dca1575",
+				rendered := model.RenderResult{
 					Manifests: []model.Manifest{
 						{
 							Name:    "deployment.yaml",
 							Content: "kind: Deployment",
 						},
 					},
+					Notes:  "Get the application URL by running these commands",
+					Values: map[string]interface{}{"ingress": false, "replicaCount": 1},
 				}
-				manifestResponseByte, _ := json.Marshal(manifestReponse)
-				ff.repository.On("Set", cacheKey, string(manifestResponseByte)).Return(nil)
+
+				values := map[string]interface{}{"ingress": false}
+				ff.helm.On("RenderManifest", "https://chart.stable.com", aa.chartName, aa.chartVersion, aa.chartName, values, aa.options, (*model.KubernetesAPIVersion)(nil)).Return(rendered, nil)
+
+				stringifiedCache := `{"url":"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/85b82e959e552f5de7f7b72aa7f5f5778560a1f0b6087412f4df58b75ed40347","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}],"notes":"Get the application URL by running these commands","values":{"ingress":false,"replicaCount":1}}`
+				ff.repository.On("Set", cacheKey, stringifiedCache).Return(nil)
 			},
 		},
 	}
@@ -755,14 +1008,32 @@ func Test_service_RenderManifest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.fields, tt.args)
 
-			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient)
-			actual, err := svc.RenderManifest(tt.args.repoName, tt.args.chartName, tt.args.chartVersion, tt.args.values)
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
+			actual, err := svc.RenderManifest(tt.args.repoName, tt.args.chartName, tt.args.chartVersion, tt.args.chartName, tt.args.values, tt.args.options)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, actual)
 		})
 	}
 }
 
+func Test_service_GetRenderValues(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "manifests-stable-app-deploy-v0.0.1-85b82e959e552f5de7f7b72aa7f5f5778560a1f0b6087412f4df58b75ed40347").Return(`{"manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}],"values":{"ingress":false,"replicaCount":1}}`, nil)
+	repository.On("Get", "manifests-stable-app-deploy-v0.0.1-e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0").Return(`{"manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}]}`, nil)
+	repository.On("Get", "manifests-stable-app-deploy-v0.0.1-unknown").Return("", nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.GetRenderValues("stable", "app-deploy", "v0.0.1", "85b82e959e552f5de7f7b72aa7f5f5778560a1f0b6087412f4df58b75ed40347")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ingress": false, "replicaCount": float64(1)}, actual)
+
+	_, err = svc.GetRenderValues("stable", "app-deploy", "v0.0.1", "e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0")
+	assert.ErrorIs(t, err, model.ErrRenderNotFound)
+
+	_, err = svc.GetRenderValues("stable", "app-deploy", "v0.0.1", "unknown")
+	assert.ErrorIs(t, err, model.ErrRenderNotFound)
+}
+
 func Test_service_GetStringifiedManifests(t *testing.T) {
 	type fields struct {
 		helm       *mocks.Helm
@@ -775,6 +1046,7 @@ func Test_service_GetStringifiedManifests(t *testing.T) {
 		chartName    string
 		chartVersion string
 		hash         string
+		withNotes    bool
 	}
 	tests := []struct {
 		name    string
@@ -804,6 +1076,47 @@ func Test_service_GetStringifiedManifests(t *testing.T) {
 				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
 			},
 		},
+		{
+			name: "should write the CRDs before the manifests",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "app-deploy",
+				chartVersion: "v0.0.1",
+				hash:         "hash",
+			},
+			want:    "---\nkind: CustomResourceDefinition\n---\nkind: Deployment\n",
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, aa.hash)
+
+				stringifiedManifest := `{"url":"rest://chart-viewer.com","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}],"crds":[{"name":"crd.yaml","content":"kind: CustomResourceDefinition"}]}`
+				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
+			},
+		},
+		{
+			name: "should append commented notes when requested",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "app-deploy",
+				chartVersion: "v0.0.1",
+				hash:         "hash",
+				withNotes:    true,
+			},
+			want:    "---\nkind: Deployment\n---\n# NOTES:\n# Visit http://app-deploy.example\n#\n# Enjoy!\n",
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, aa.hash)
+
+				stringifiedManifest := `{"url":"rest://chart-viewer.com","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}],"notes":"Visit http://app-deploy.example\n\nEnjoy!\n"}`
+				ff.repository.On("Get", cacheKey).Return(stringifiedManifest, nil)
+			},
+		},
 		{
 			name: "should failed if repository return error when getting cache",
 			fields: fields{
@@ -823,15 +1136,961 @@ func Test_service_GetStringifiedManifests(t *testing.T) {
 				ff.repository.On("Get", cacheKey).Return("", errors.New("error"))
 			},
 		},
+		{
+			name: "should failed if the render is not stored",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "app-deploy",
+				chartVersion: "v0.0.1",
+				hash:         "hash",
+			},
+			want:    "",
+			wantErr: fmt.Errorf("%w: hash", model.ErrRenderNotFound),
+			mockFn: func(ff fields, aa args) {
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, aa.hash)
+
+				ff.repository.On("Get", cacheKey).Return("", nil)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockFn(tt.fields, tt.args)
 
-			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient)
-			actual, err := svc.GetStringifiedManifests(tt.args.repoName, tt.args.chartName, tt.args.chartVersion, tt.args.hash)
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
+			actual, err := svc.GetStringifiedManifests(tt.args.repoName, tt.args.chartName, tt.args.chartVersion, tt.args.hash, tt.args.withNotes)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.want, actual)
 		})
 	}
 }
+
+func Test_service_AnalyzeManifest(t *testing.T) {
+	type fields struct {
+		helm       *mocks.Helm
+		repository *mocks.Repository
+		analyzer   *mocks.Analytic
+		httpClient *mocks.HTTPClient
+	}
+	type args struct {
+		repoName     string
+		chartName    string
+		chartVersion string
+		values       string
+		options      model.RenderOptions
+		kubeVersion  string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []model.ManifestAnalyticsResult
+		wantErr error
+		mockFn  func(ff fields, aa args)
+	}{
+		{
+			name: "should analyze the rendered manifests",
+			fields: fields{
+				helm:       new(mocks.Helm),
+				repository: new(mocks.Repository),
+				analyzer:   new(mocks.Analytic),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "aap-deploy",
+				chartVersion: "v0.0.1",
+				values:       `{"ingress": false}`,
+				kubeVersion:  "1.16",
+			},
+			want: []model.ManifestAnalyticsResult{
+				{
+					Manifest: model.Manifest{
+						Name:     "deployment.yaml",
+						Template: "templates/deployment.yaml",
+						Content:  "apiVersion: apps/v1\nkind: Deployment",
+					},
+					Compatible: true,
+					Resources: []model.ResourceAnalytics{
+						{APIVersion: "apps/v1", Kind: "Deployment", Compatible: true},
+					},
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				ff.repository.On("Get", "api-versions").Return(`[{"kube_version":"1.16","api_versions":["apps/v1"]}]`, nil)
+				ff.repository.On("Get", "api-deprecations").Return(`[{"group":"apps","version":"v1beta1","kind":"Deployment","deprecated_in":"1.9","removed_in":"1.16","replacement":{"group":"apps","version":"v1","kind":"Deployment"}}]`, nil)
+
+				manifests := []model.Manifest{
+					{
+						Name:     "deployment.yaml",
+						Template: "templates/deployment.yaml",
+						Content:  "apiVersion: apps/v1\nkind: Deployment",
+					},
+				}
+				kubeAPIVersion := model.KubernetesAPIVersion{KubeVersion: "1.16", APIVersions: []string{"apps/v1"}}
+
+				// The chart renders with the capabilities of the analyzed
+				// release, under its own cache entry.
+				hash := "70156b6082a1cf5a92ffd1c12955f21ab3ffba180f4b51ecb03b0c85818852a4"
+				cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", aa.repoName, aa.chartName, aa.chartVersion, hash)
+				ff.repository.On("Get", cacheKey).Return("", nil)
+				ff.repository.On("Get", "repos").Return(`[{"name":"stable","url":"https://chart.stable.com"}]`, nil)
+				ff.helm.On("RenderManifest", "https://chart.stable.com", aa.chartName, aa.chartVersion, aa.chartName, map[string]interface{}{"ingress": false}, aa.options, &kubeAPIVersion).Return(model.RenderResult{Manifests: manifests}, nil)
+				manifestsByte, _ := json.Marshal(model.ManifestResponse{
+					URL:       "/api/v1/charts/manifests/stable/aap-deploy/v0.0.1/" + hash,
+					Manifests: manifests,
+				})
+				ff.repository.On("Set", cacheKey, string(manifestsByte)).Return(nil)
+				deprecations := []model.APIDeprecation{
+					{
+						GroupVersionKind: model.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"},
+						DeprecatedIn:     "1.9",
+						RemovedIn:        "1.16",
+						Replacement:      &model.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+					},
+				}
+				ff.analyzer.On("AnalyzeManifests", manifests, kubeAPIVersion, deprecations).Return([]model.ManifestAnalyticsResult{
+					{
+						Manifest:   manifests[0],
+						Compatible: true,
+						Resources: []model.ResourceAnalytics{
+							{APIVersion: "apps/v1", Kind: "Deployment", Compatible: true},
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			name: "should failed if repository return error when getting api versions",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "aap-deploy",
+				chartVersion: "v0.0.1",
+				kubeVersion:  "1.16",
+			},
+			want:    nil,
+			wantErr: errors.New("error"),
+			mockFn: func(ff fields, aa args) {
+				ff.repository.On("Get", "api-versions").Return("", errors.New("error"))
+			},
+		},
+		{
+			name: "should failed if repository return error when getting api deprecations",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			args: args{
+				repoName:     "stable",
+				chartName:    "aap-deploy",
+				chartVersion: "v0.0.1",
+				kubeVersion:  "1.16",
+			},
+			want:    nil,
+			wantErr: errors.New("error"),
+			mockFn: func(ff fields, aa args) {
+				ff.repository.On("Get", "api-versions").Return(`[{"kube_version":"1.16","api_versions":["apps/v1"]}]`, nil)
+				ff.repository.On("Get", "api-deprecations").Return("", errors.New("error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields, tt.args)
+
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
+			actual, err := svc.AnalyzeManifest(tt.args.repoName, tt.args.chartName, tt.args.chartVersion, tt.args.values, tt.args.options, tt.args.kubeVersion)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_service_GetCompatibilityMatrix(t *testing.T) {
+	type fields struct {
+		helm       *mocks.Helm
+		repository *mocks.Repository
+		analyzer   *mocks.Analytic
+		httpClient *mocks.HTTPClient
+	}
+	type args struct {
+		repoName  string
+		chartName string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    model.CompatibilityMatrix
+		wantErr error
+		mockFn  func(ff fields, aa args)
+	}{
+		{
+			name: "should return the kubernetes versions each chart version is compatible with",
+			fields: fields{
+				repository: new(mocks.Repository),
+				analyzer:   new(mocks.Analytic),
+			},
+			args: args{repoName: "stable", chartName: "app-deploy"},
+			want: model.CompatibilityMatrix{
+				Repo:         "stable",
+				Chart:        "app-deploy",
+				KubeVersions: []string{"1.9", "1.16", "1.22"},
+				Versions: []model.ChartCompatibility{
+					{Version: "v0.0.2", MinKubeVersion: "1.16", MaxKubeVersion: "1.22", KubeVersions: []string{"1.16", "1.22"}, UnknownKubeVersions: []string{}},
+					{Version: "v0.0.1", MinKubeVersion: "1.9", MaxKubeVersion: "1.16", KubeVersions: []string{"1.9", "1.16"}, UnknownKubeVersions: []string{}},
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				ff.repository.On("Get", "stable").Return(`[{"name":"app-deploy","versions":["v0.0.2","v0.0.1"]}]`, nil)
+				ff.repository.On("Get", "api-versions").Return(`[{"kube_version":"1.22","api_versions":["networking.k8s.io/v1"]},{"kube_version":"1.9","api_versions":["extensions/v1beta1"]},{"kube_version":"1.16","api_versions":["extensions/v1beta1","networking.k8s.io/v1"]}]`, nil)
+				ff.repository.On("Get", "api-deprecations").Return(`[]`, nil)
+				ff.repository.On("Get", "template-stable-app-deploy-v0.0.2").Return(`[{"name":"templates/ingress.yaml","content":"apiVersion: networking.k8s.io/v1"}]`, nil)
+				ff.repository.On("Get", "template-stable-app-deploy-v0.0.1").Return(`[{"name":"templates/ingress.yaml","content":"apiVersion: extensions/v1beta1"}]`, nil)
+
+				newTemplates := []model.Template{{Name: "templates/ingress.yaml", Content: "apiVersion: networking.k8s.io/v1"}}
+				oldTemplates := []model.Template{{Name: "templates/ingress.yaml", Content: "apiVersion: extensions/v1beta1"}}
+				deprecations := []model.APIDeprecation{}
+				for _, k := range []model.KubernetesAPIVersion{
+					{KubeVersion: "1.9", APIVersions: []string{"extensions/v1beta1"}},
+					{KubeVersion: "1.16", APIVersions: []string{"extensions/v1beta1", "networking.k8s.io/v1"}},
+					{KubeVersion: "1.22", APIVersions: []string{"networking.k8s.io/v1"}},
+				} {
+					ff.analyzer.On("Analyze", newTemplates, k, deprecations).Return([]model.AnalyticsResult{
+						{Template: newTemplates[0], Compatible: k.KubeVersion != "1.9"},
+					}, nil)
+					ff.analyzer.On("Analyze", oldTemplates, k, deprecations).Return([]model.AnalyticsResult{
+						{Template: oldTemplates[0], Compatible: k.KubeVersion != "1.22"},
+					}, nil)
+				}
+			},
+		},
+		{
+			name: "should list every compatible version and the versions the templates cannot be checked on",
+			fields: fields{
+				repository: new(mocks.Repository),
+				analyzer:   new(mocks.Analytic),
+			},
+			args: args{repoName: "stable", chartName: "app-deploy"},
+			want: model.CompatibilityMatrix{
+				Repo:         "stable",
+				Chart:        "app-deploy",
+				KubeVersions: []string{"1.9", "1.16", "1.22", "1.25"},
+				Versions: []model.ChartCompatibility{
+					{Version: "v0.0.1", MinKubeVersion: "1.9", MaxKubeVersion: "1.25", KubeVersions: []string{"1.9", "1.25"}, UnknownKubeVersions: []string{"1.22"}},
+				},
+			},
+			wantErr: nil,
+			mockFn: func(ff fields, aa args) {
+				ff.repository.On("Get", "stable").Return(`[{"name":"app-deploy","versions":["v0.0.1"]}]`, nil)
+				ff.repository.On("Get", "api-versions").Return(`[{"kube_version":"1.9","api_versions":["v1"]},{"kube_version":"1.16","api_versions":["v1"]},{"kube_version":"1.22","api_versions":["v1"]},{"kube_version":"1.25","api_versions":["v1"]}]`, nil)
+				ff.repository.On("Get", "api-deprecations").Return(`[]`, nil)
+				ff.repository.On("Get", "template-stable-app-deploy-v0.0.1").Return(`[{"name":"templates/ingress.yaml","content":"apiVersion: {{ .Values.apiVersion }}"}]`, nil)
+
+				templates := []model.Template{{Name: "templates/ingress.yaml", Content: "apiVersion: {{ .Values.apiVersion }}"}}
+				ff.analyzer.On("Analyze", templates, mock.Anything, []model.APIDeprecation{}).Return(func(templates []model.Template, k model.KubernetesAPIVersion, deprecations []model.APIDeprecation) []model.AnalyticsResult {
+					return []model.AnalyticsResult{
+						{Template: templates[0], Compatible: k.KubeVersion != "1.16", Unchecked: k.KubeVersion == "1.22"},
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "should failed if the chart does not exist in the repository",
+			fields: fields{
+				repository: new(mocks.Repository),
+			},
+			args:    args{repoName: "stable", chartName: "unknown"},
+			want:    model.CompatibilityMatrix{},
+			wantErr: fmt.Errorf("%w: unknown in stable", model.ErrChartNotFound),
+			mockFn: func(ff fields, aa args) {
+				ff.repository.On("Get", "stable").Return(`[{"name":"app-deploy","versions":["v0.0.1"]}]`, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields, tt.args)
+
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, tt.fields.analyzer, tt.fields.httpClient, nil)
+			actual, err := svc.GetCompatibilityMatrix(tt.args.repoName, tt.args.chartName)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_service_AnalyzeTemplate_unknownKubeVersion(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "api-versions").Return(`[{"kube_version":"1.16","api_versions":["apps/v1"]}]`, nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	_, err := svc.AnalyzeTemplate([]model.Template{}, "1.99")
+	assert.ErrorIs(t, err, model.ErrUnknownKubeVersion)
+}
+
+func Test_service_AnalyzeTemplate_withoutDeprecations(t *testing.T) {
+	repository := new(mocks.Repository)
+	analyzer := new(mocks.Analytic)
+
+	repository.On("Get", "api-versions").Return(`[{"kube_version":"1.16","api_versions":["apps/v1"]}]`, nil)
+	repository.On("Get", "api-deprecations").Return("", nil)
+
+	templates := []model.Template{{Name: "templates/deployment.yaml", Content: "apiVersion: apps/v1"}}
+	kubeAPIVersion := model.KubernetesAPIVersion{KubeVersion: "1.16", APIVersions: []string{"apps/v1"}}
+	want := []model.AnalyticsResult{{Template: templates[0], Compatible: true}}
+	analyzer.On("Analyze", templates, kubeAPIVersion, []model.APIDeprecation{}).Return(want, nil)
+
+	svc := service.NewService(nil, repository, analyzer, nil, nil)
+	actual, err := svc.AnalyzeTemplate(templates, "1.16")
+	assert.NoError(t, err)
+	assert.Equal(t, want, actual)
+}
+
+func Test_service_ValidateManifest(t *testing.T) {
+	helm := new(mocks.Helm)
+	repository := new(mocks.Repository)
+	validator := new(mocks.Validator)
+
+	repository.On("Get", "api-versions").Return(`[{"kube_version":"1.16","api_versions":["apps/v1"]}]`, nil)
+	repository.On("Get", "repos").Return(`[{"name":"stable","url":"https://chart.stable.com"}]`, nil)
+
+	// The chart renders with the capabilities of the validated release,
+	// under its own cache entry.
+	hash := "70156b6082a1cf5a92ffd1c12955f21ab3ffba180f4b51ecb03b0c85818852a4"
+	cacheKey := "manifests-stable-aap-deploy-v0.0.1-" + hash
+	repository.On("Get", cacheKey).Return("", nil)
+
+	manifests := []model.Manifest{{Name: "crontab.yaml", Content: "apiVersion: stable.example.com/v1\nkind: CronTab"}}
+	crds := []model.Manifest{{Name: "crontab.yaml", Template: "crds/crontab.yaml", Content: "kind: CustomResourceDefinition"}}
+	kubeAPIVersion := model.KubernetesAPIVersion{KubeVersion: "1.16", APIVersions: []string{"apps/v1"}}
+	helm.On("RenderManifest", "https://chart.stable.com", "aap-deploy", "v0.0.1", "aap-deploy", map[string]interface{}{"ingress": false}, model.RenderOptions{}, &kubeAPIVersion).Return(model.RenderResult{Manifests: manifests, CRDs: crds}, nil)
+	manifestsByte, _ := json.Marshal(model.ManifestResponse{
+		URL:       "/api/v1/charts/manifests/stable/aap-deploy/v0.0.1/" + hash,
+		Manifests: manifests,
+		CRDs:      crds,
+	})
+	repository.On("Set", cacheKey, string(manifestsByte)).Return(nil)
+
+	want := []model.ManifestValidationResult{
+		{Name: "crontab.yaml", APIVersion: "stable.example.com/v1", Kind: "CronTab", Status: "valid"},
+	}
+	validator.On("Validate", manifests, crds, "1.16").Return(want, nil)
+
+	svc := service.NewService(helm, repository, nil, nil, validator)
+	actual, err := svc.ValidateManifest("stable", "aap-deploy", "v0.0.1", `{"ingress": false}`, model.RenderOptions{}, "1.16")
+	assert.NoError(t, err)
+	assert.Equal(t, want, actual)
+}
+
+func Test_service_GetChartReport(t *testing.T) {
+	repository := new(mocks.Repository)
+	analyzer := new(mocks.Analytic)
+
+	cacheKey := "manifests-stable-aap-deploy-v0.0.1-e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0"
+	repository.On("Get", cacheKey).Return(`{"url":"/api/v1/charts/manifests/stable/aap-deploy/v0.0.1/e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0","manifests":[{"name":"deployment.yaml","content":"kind: Deployment"}]}`, nil)
+
+	findings := []model.RuleFinding{
+		{RuleID: "privileged-container", Severity: "high", Manifest: "deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container runs in privileged mode"},
+		{RuleID: "missing-liveness-probe", Severity: "low", Manifest: "deployment.yaml", Kind: "Deployment", Resource: "app", Container: "app", Message: "container has no livenessProbe"},
+		{RuleID: "missing-liveness-probe", Severity: "low", Manifest: "deployment.yaml", Kind: "Deployment", Resource: "app", Container: "sidecar", Message: "container has no livenessProbe"},
+	}
+	repository.On("Get", "policies").Return(`[{"id":"no-load-balancer","severity":"medium","kinds":["Service"],"expression":"object.spec.type != 'LoadBalancer'"}]`, nil)
+	policies := []model.Policy{
+		{ID: "no-load-balancer", Severity: "medium", Kinds: []string{"Service"}, Expression: "object.spec.type != 'LoadBalancer'"},
+	}
+	analyzer.On("Check", []model.Manifest{{Name: "deployment.yaml", Content: "kind: Deployment"}}, policies).Return(findings, nil)
+
+	svc := service.NewService(nil, repository, analyzer, nil, nil)
+	actual, err := svc.GetChartReport("stable", "aap-deploy", "v0.0.1", `{"ingress": false}`, model.RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.ChartReport{
+		Repo:    "stable",
+		Chart:   "aap-deploy",
+		Version: "v0.0.1",
+		Summary: model.ReportSummary{
+			Total:      3,
+			BySeverity: map[string]int{"high": 1, "low": 2},
+			ByRule:     map[string]int{"privileged-container": 1, "missing-liveness-probe": 2},
+		},
+		Findings: findings,
+	}, actual)
+}
+
+func Test_service_RenderBatch(t *testing.T) {
+	type fields struct {
+		helm       *mocks.Helm
+		repository *mocks.Repository
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		releases []model.BatchRelease
+		want     model.BatchRender
+		wantErr  error
+		mockFn   func(ff fields)
+	}{
+		{
+			name:   "should render the releases and report the objects rendered by several releases",
+			fields: fields{helm: new(mocks.Helm), repository: new(mocks.Repository)},
+			releases: []model.BatchRelease{
+				{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0"},
+				{Name: "worker", Repo: "stable", Chart: "app", Version: "1.1.0", Values: "role: worker"},
+				{Repo: "stable", Chart: "db", Version: "2.0.0"},
+				{Name: "cache", Repo: "stable", Chart: "redis"},
+			},
+			want: model.BatchRender{
+				Succeeded: 2,
+				Failed:    2,
+				Releases: []model.BatchReleaseResult{
+					{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0", URL: "/api/v1/charts/manifests/stable/app/1.0.0/web"},
+					{Name: "worker", Repo: "stable", Chart: "app", Version: "1.1.0", URL: "/api/v1/charts/manifests/stable/app/1.1.0/worker"},
+					{
+						Name:    "db",
+						Repo:    "stable",
+						Chart:   "db",
+						Version: "2.0.0",
+						Error:   "execution error at (db/templates/secret.yaml:3:10): password is required",
+						Diagnostics: []model.RenderDiagnostic{
+							{Phase: "execute", Template: "templates/secret.yaml", Line: 3, Column: 10, Message: "password is required"},
+						},
+					},
+					{Name: "cache", Repo: "stable", Chart: "redis", Error: "the repo, chart and version are required"},
+				},
+				Manifests: []model.ReleaseManifest{
+					{Release: "web", Manifest: model.Manifest{Name: "crd.yaml", Content: "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: jobs.acme.io"}},
+					{Release: "web", Manifest: model.Manifest{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web"}},
+					{Release: "worker", Manifest: model.Manifest{Name: "crd.yaml", Content: "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: jobs.acme.io"}},
+					{Release: "worker", Manifest: model.Manifest{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: worker"}},
+				},
+				Duplicates: []model.DuplicateObject{
+					{
+						ObjectIdentity: model.ObjectIdentity{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "jobs.acme.io"},
+						Occurrences: []model.ObjectOccurrence{
+							{Release: "web", Manifest: "crd.yaml", APIVersion: "apiextensions.k8s.io/v1"},
+							{Release: "worker", Manifest: "crd.yaml", APIVersion: "apiextensions.k8s.io/v1"},
+						},
+					},
+				},
+			},
+			mockFn: func(ff fields) {
+				for name, version := range map[string]string{"web": "1.0.0", "worker": "1.1.0"} {
+					prefix := fmt.Sprintf("manifests-stable-app-%s-", version)
+					rendered := fmt.Sprintf(`{"url":"/api/v1/charts/manifests/stable/app/%s/%s","manifests":[{"name":"deployment.yaml","content":"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: %s"}],"crds":[{"name":"crd.yaml","content":"apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: jobs.acme.io"}]}`, version, name, name)
+					ff.repository.On("Get", mock.MatchedBy(func(key string) bool {
+						return strings.HasPrefix(key, prefix)
+					})).Return(rendered, nil)
+				}
+
+				ff.repository.On("Get", mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "manifests-stable-db-2.0.0-")
+				})).Return("", nil)
+				ff.repository.On("Get", "repos").Return(`[{"name":"stable","url":"https://chart.stable.com"}]`, nil)
+
+				renderErr := &model.RenderError{
+					Message: "execution error at (db/templates/secret.yaml:3:10): password is required",
+					Diagnostics: []model.RenderDiagnostic{
+						{Phase: "execute", Template: "templates/secret.yaml", Line: 3, Column: 10, Message: "password is required"},
+					},
+				}
+				ff.helm.On("RenderManifest", "https://chart.stable.com", "db", "2.0.0", "db", map[string]interface{}{}, model.RenderOptions{}, (*model.KubernetesAPIVersion)(nil)).Return(model.RenderResult{}, renderErr)
+			},
+		},
+		{
+			name:   "should report a release whose manifests cannot be parsed on that release",
+			fields: fields{helm: new(mocks.Helm), repository: new(mocks.Repository)},
+			releases: []model.BatchRelease{
+				{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0"},
+				{Name: "broken", Repo: "stable", Chart: "app", Version: "1.1.0"},
+			},
+			want: model.BatchRender{
+				Succeeded: 1,
+				Failed:    1,
+				Releases: []model.BatchReleaseResult{
+					{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0", URL: "/api/v1/charts/manifests/stable/app/1.0.0/web"},
+					{
+						Name:    "broken",
+						Repo:    "stable",
+						Chart:   "app",
+						Version: "1.1.0",
+						URL:     "/api/v1/charts/manifests/stable/app/1.1.0/broken",
+						Error:   "cannot check duplicates: cannot parse manifest deployment.yaml: yaml: line 1: did not find expected ',' or ']'",
+					},
+				},
+				Manifests: []model.ReleaseManifest{
+					{Release: "web", Manifest: model.Manifest{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web"}},
+				},
+				Duplicates: []model.DuplicateObject{},
+			},
+			mockFn: func(ff fields) {
+				ff.repository.On("Get", mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "manifests-stable-app-1.0.0-")
+				})).Return(`{"url":"/api/v1/charts/manifests/stable/app/1.0.0/web","manifests":[{"name":"deployment.yaml","content":"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web"}]}`, nil)
+				ff.repository.On("Get", mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "manifests-stable-app-1.1.0-")
+				})).Return(`{"url":"/api/v1/charts/manifests/stable/app/1.1.0/broken","manifests":[{"name":"deployment.yaml","content":"kind: [Deployment"}]}`, nil)
+			},
+		},
+		{
+			name:   "should return an error when a release is defined twice",
+			fields: fields{helm: new(mocks.Helm), repository: new(mocks.Repository)},
+			releases: []model.BatchRelease{
+				{Repo: "stable", Chart: "app", Version: "1.0.0"},
+				{Repo: "stable", Chart: "app", Version: "2.0.0"},
+			},
+			wantErr: model.ErrInvalidBatch,
+			mockFn:  func(ff fields) {},
+		},
+		{
+			name:     "should return an error when no release is given",
+			fields:   fields{helm: new(mocks.Helm), repository: new(mocks.Repository)},
+			releases: []model.BatchRelease{},
+			wantErr:  model.ErrInvalidBatch,
+			mockFn:   func(ff fields) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			svc := service.NewService(tt.fields.helm, tt.fields.repository, nil, nil, nil)
+			got, err := svc.RenderBatch(tt.releases)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_service_RenderBatch_releaseNames(t *testing.T) {
+	helm := new(mocks.Helm)
+	repository := new(mocks.Repository)
+
+	repository.On("Get", "repos").Return(`[{"name":"stable","url":"https://chart.stable.com"}]`, nil)
+	repository.On("Get", mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "manifests-stable-app-1.0.0-")
+	})).Return("", nil)
+
+	var mu sync.Mutex
+	var cacheKeys []string
+	repository.On("Set", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		cacheKeys = append(cacheKeys, args.String(0))
+	}).Return(nil)
+
+	for _, name := range []string{"web", "worker"} {
+		rendered := model.RenderResult{
+			Manifests: []model.Manifest{
+				{Name: "deployment.yaml", Content: fmt.Sprintf("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: %s-app", name)},
+			},
+		}
+		helm.On("RenderManifest", "https://chart.stable.com", "app", "1.0.0", name, map[string]interface{}{}, model.RenderOptions{}, (*model.KubernetesAPIVersion)(nil)).Return(rendered, nil)
+	}
+
+	svc := service.NewService(helm, repository, nil, nil, nil)
+	got, err := svc.RenderBatch([]model.BatchRelease{
+		{Name: "web", Repo: "stable", Chart: "app", Version: "1.0.0"},
+		{Name: "worker", Repo: "stable", Chart: "app", Version: "1.0.0"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.Succeeded)
+	assert.Empty(t, got.Duplicates)
+	assert.Equal(t, "web", got.Manifests[0].Release)
+	assert.Contains(t, got.Manifests[0].Content, "name: web-app")
+	assert.Equal(t, "worker", got.Manifests[1].Release)
+	assert.Contains(t, got.Manifests[1].Content, "name: worker-app")
+
+	// The releases only differ in name, which must give them their own
+	// cache entries.
+	assert.Len(t, cacheKeys, 2)
+	assert.NotEqual(t, cacheKeys[0], cacheKeys[1])
+	assert.NotEqual(t, got.Releases[0].URL, got.Releases[1].URL)
+}
+
+func Test_service_DiffValues(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "value-stable-aap-deploy-v0.0.1").Return(`{"ingress": {"enabled": false}, "legacy": true}`, nil)
+	repository.On("Get", "value-stable-aap-deploy-v0.0.2").Return(`{"ingress": {"enabled": true, "className": "nginx"}}`, nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.DiffValues("stable", "aap-deploy", "v0.0.1", "v0.0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, model.ValuesDiff{
+		Repo:    "stable",
+		Chart:   "aap-deploy",
+		From:    "v0.0.1",
+		To:      "v0.0.2",
+		Added:   []model.ValueChange{{Path: "/ingress/className", New: "nginx"}},
+		Removed: []model.ValueChange{{Path: "/legacy", Old: true}},
+		Changed: []model.ValueChange{{Path: "/ingress/enabled", Old: false, New: true}},
+	}, actual)
+
+	repository.On("Get", "value-stable-aap-deploy-v0.0.3").Return("", errors.New("error"))
+	_, err = svc.DiffValues("stable", "aap-deploy", "v0.0.1", "v0.0.3")
+	assert.Equal(t, errors.New("error"), err)
+}
+
+func Test_service_DiffTemplates(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "template-stable-aap-deploy-v0.0.1").Return(`[{"name":"templates/svc.yaml","content":"kind: Service\nport: 80\n"}]`, nil)
+	repository.On("Get", "template-stable-aap-deploy-v0.0.2").Return(`[{"name":"templates/service.yaml","content":"kind: Service\nport: 80\n"}]`, nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.DiffTemplates("stable", "aap-deploy", "v0.0.1", "v0.0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, model.TemplatesDiff{
+		Repo:     "stable",
+		Chart:    "aap-deploy",
+		From:     "v0.0.1",
+		To:       "v0.0.2",
+		Added:    []model.TemplateChange{},
+		Removed:  []model.TemplateChange{},
+		Modified: []model.TemplateChange{},
+		Renamed: []model.TemplateChange{{
+			Name:       "templates/service.yaml",
+			OldName:    "templates/svc.yaml",
+			Similarity: 100,
+		}},
+	}, actual)
+
+	repository.On("Get", "template-stable-aap-deploy-v0.0.3").Return("", errors.New("error"))
+	_, err = svc.DiffTemplates("stable", "aap-deploy", "v0.0.1", "v0.0.3")
+	assert.Equal(t, errors.New("error"), err)
+}
+
+func Test_service_DiffManifests(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.1-e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0").Return(`{"manifests":[{"name":"service.yaml","content":"apiVersion: v1\nkind: Service\nmetadata:\n  name: app\n"}]}`, nil)
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.2-2b7ab404ee2e491975df77e2fde73c831af86fd3e9321fc32fb51339a372d203").Return(`{"manifests":[{"name":"service.yaml","content":"# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata: {name: app}\n"}]}`, nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.DiffManifests("stable", "aap-deploy",
+		model.RenderSpec{Version: "v0.0.1", Values: `{"ingress": false}`},
+		model.RenderSpec{Version: "v0.0.2", Values: `{"ingress": false}`},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ManifestDiff{
+		Repo:      "stable",
+		Chart:     "aap-deploy",
+		From:      "v0.0.1",
+		To:        "v0.0.2",
+		Added:     []model.ObjectDiff{},
+		Removed:   []model.ObjectDiff{},
+		Changed:   []model.ObjectDiff{},
+		Unchanged: 1,
+	}, actual)
+}
+
+func Test_service_CompareImages(t *testing.T) {
+	repository := new(mocks.Repository)
+	analyzer := new(mocks.Analytic)
+
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.1-e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0").Return(`{"manifests":[{"name":"deployment.yaml","content":"image: nginx:1.24"}]}`, nil)
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.2-2b7ab404ee2e491975df77e2fde73c831af86fd3e9321fc32fb51339a372d203").Return(`{"manifests":[{"name":"deployment.yaml","content":"image: nginx:1.25"}]}`, nil)
+
+	nginx124 := model.ContainerImage{Image: "docker.io/library/nginx:1.24", Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"}
+	nginx125 := model.ContainerImage{Image: "docker.io/library/nginx:1.25", Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}
+	busybox := model.ContainerImage{Image: "docker.io/library/busybox:1.36", Registry: "docker.io", Repository: "library/busybox", Tag: "1.36"}
+	analyzer.On("Images", []model.Manifest{{Name: "deployment.yaml", Content: "image: nginx:1.24"}}).Return([]model.ContainerImage{nginx124}, nil)
+	analyzer.On("Images", []model.Manifest{{Name: "deployment.yaml", Content: "image: nginx:1.25"}}).Return([]model.ContainerImage{busybox, nginx125}, nil)
+	analyzer.On("CompareImages", []model.ContainerImage{nginx124}, []model.ContainerImage{busybox, nginx125}).Return(model.ImageComparison{
+		Added:     []model.ContainerImage{busybox},
+		Removed:   []model.ContainerImage{},
+		Changed:   []model.ImageChange{{Registry: "docker.io", Repository: "library/nginx", From: nginx124, To: nginx125}},
+		Unchanged: []model.ContainerImage{},
+	})
+
+	svc := service.NewService(nil, repository, analyzer, nil, nil)
+	actual, err := svc.CompareImages("stable", "aap-deploy", "v0.0.1", "v0.0.2", `{"ingress": false}`, model.RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.ImageComparison{
+		Repo:      "stable",
+		Chart:     "aap-deploy",
+		From:      "v0.0.1",
+		To:        "v0.0.2",
+		Added:     []model.ContainerImage{busybox},
+		Removed:   []model.ContainerImage{},
+		Changed:   []model.ImageChange{{Registry: "docker.io", Repository: "library/nginx", From: nginx124, To: nginx125}},
+		Unchanged: []model.ContainerImage{},
+	}, actual)
+	analyzer.AssertExpectations(t)
+}
+
+func Test_service_GetUpgradeImpact(t *testing.T) {
+	repository := new(mocks.Repository)
+	analyzer := new(mocks.Analytic)
+
+	fromDeployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  selector:\n    matchLabels:\n      app: app\n"
+	toDeployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  selector:\n    matchLabels:\n      app.kubernetes.io/name: app\n"
+	fromManifests := []model.Manifest{
+		{Name: "deployment.yaml", Content: fromDeployment},
+		{Name: "job.yaml", Content: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n"},
+		{Name: "pdb.yaml", Content: "apiVersion: policy/v1beta1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\n"},
+	}
+	toManifests := []model.Manifest{
+		{Name: "deployment.yaml", Content: toDeployment},
+		{Name: "pdb.yaml", Content: "apiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\n"},
+	}
+	fromRender, _ := json.Marshal(model.ManifestResponse{Manifests: fromManifests})
+	toRender, _ := json.Marshal(model.ManifestResponse{Manifests: toManifests})
+
+	repository.On("Get", "value-stable-aap-deploy-v0.0.1").Return(`{"ingress": false, "legacy": true}`, nil)
+	repository.On("Get", "value-stable-aap-deploy-v0.0.2").Return(`{"networking": {"ingress": false}}`, nil)
+	// Each version is rendered once for the manifests and the images.
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.1-e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0").Return(string(fromRender), nil).Once()
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.2-2b7ab404ee2e491975df77e2fde73c831af86fd3e9321fc32fb51339a372d203").Return(string(toRender), nil).Once()
+
+	nginx124 := model.ContainerImage{Image: "docker.io/library/nginx:1.24", Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"}
+	nginx125 := model.ContainerImage{Image: "docker.io/library/nginx:1.25", Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}
+	analyzer.On("Images", fromManifests).Return([]model.ContainerImage{nginx124}, nil)
+	analyzer.On("Images", toManifests).Return([]model.ContainerImage{nginx125}, nil)
+	analyzer.On("CompareImages", []model.ContainerImage{nginx124}, []model.ContainerImage{nginx125}).Return(model.ImageComparison{
+		Added:     []model.ContainerImage{},
+		Removed:   []model.ContainerImage{},
+		Changed:   []model.ImageChange{{Registry: "docker.io", Repository: "library/nginx", From: nginx124, To: nginx125}},
+		Unchanged: []model.ContainerImage{},
+	})
+
+	svc := service.NewService(nil, repository, analyzer, nil, nil)
+	actual, err := svc.GetUpgradeImpact("stable", "aap-deploy", "v0.0.1", "v0.0.2", `{"ingress": false}`, model.RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.UpgradeImpact{
+		Repo:   "stable",
+		Chart:  "aap-deploy",
+		From:   "v0.0.1",
+		To:     "v0.0.2",
+		Values: []model.ValueImpact{{Path: "/ingress", Value: false, RenamedTo: "/networking/ingress"}},
+		Recreated: []model.RecreateImpact{{
+			ObjectIdentity: model.ObjectIdentity{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+			Manifest:       "deployment.yaml",
+			Fields:         []string{"/spec/selector"},
+		}},
+		RemovedResources: []model.ObjectIdentity{{APIVersion: "batch/v1", Kind: "Job", Name: "migrate"}},
+		ImageChanges:     []model.ImageChange{{Registry: "docker.io", Repository: "library/nginx", From: nginx124, To: nginx125}},
+		AddedImages:      []model.ContainerImage{},
+	}, actual)
+
+	repository.AssertExpectations(t)
+	analyzer.AssertExpectations(t)
+
+	_, err = svc.GetUpgradeImpact("stable", "aap-deploy", "v0.0.1", "v0.0.2", "ingress: [", model.RenderOptions{})
+	assert.ErrorContains(t, err, "cannot parse values")
+}
+
+func Test_service_SavePolicy(t *testing.T) {
+	type fields struct {
+		repository *mocks.Repository
+		analyzer   *mocks.Analytic
+	}
+	policy := model.Policy{ID: "no-load-balancer", Severity: "medium", Kinds: []string{"Service"}, Expression: "object.spec.type != 'LoadBalancer'"}
+	tests := []struct {
+		name    string
+		fields  fields
+		policy  model.Policy
+		wantErr error
+		mockFn  func(ff fields)
+	}{
+		{
+			name:    "should add a new policy",
+			fields:  fields{repository: new(mocks.Repository), analyzer: new(mocks.Analytic)},
+			policy:  policy,
+			wantErr: nil,
+			mockFn: func(ff fields) {
+				ff.analyzer.On("CompilePolicy", policy).Return(nil)
+				ff.repository.On("Get", "policies").Return(`[{"id":"name-label","severity":"low","expression":"true"}]`, nil)
+				ff.repository.On("Set", "policies", `[{"id":"name-label","severity":"low","expression":"true"},{"id":"no-load-balancer","severity":"medium","kinds":["Service"],"expression":"object.spec.type != 'LoadBalancer'"}]`).Return(nil)
+			},
+		},
+		{
+			name:    "should replace a policy with the same id",
+			fields:  fields{repository: new(mocks.Repository), analyzer: new(mocks.Analytic)},
+			policy:  policy,
+			wantErr: nil,
+			mockFn: func(ff fields) {
+				ff.analyzer.On("CompilePolicy", policy).Return(nil)
+				ff.repository.On("Get", "policies").Return(`[{"id":"no-load-balancer","severity":"low","expression":"true"}]`, nil)
+				ff.repository.On("Set", "policies", `[{"id":"no-load-balancer","severity":"medium","kinds":["Service"],"expression":"object.spec.type != 'LoadBalancer'"}]`).Return(nil)
+			},
+		},
+		{
+			name:    "should not store a policy that does not compile",
+			fields:  fields{repository: new(mocks.Repository), analyzer: new(mocks.Analytic)},
+			policy:  model.Policy{ID: "broken", Severity: "low", Expression: "object."},
+			wantErr: model.ErrInvalidPolicy,
+			mockFn: func(ff fields) {
+				ff.analyzer.On("CompilePolicy", model.Policy{ID: "broken", Severity: "low", Expression: "object."}).Return(model.ErrInvalidPolicy)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn(tt.fields)
+
+			svc := service.NewService(nil, tt.fields.repository, tt.fields.analyzer, nil, nil)
+			err := svc.SavePolicy(tt.policy)
+			assert.Equal(t, tt.wantErr, err)
+			tt.fields.repository.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_service_DeletePolicy(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "policies").Return(`[{"id":"name-label","severity":"low","expression":"true"},{"id":"no-load-balancer","severity":"medium","expression":"true"}]`, nil)
+	repository.On("Set", "policies", `[{"id":"no-load-balancer","severity":"medium","expression":"true"}]`).Return(nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	assert.NoError(t, svc.DeletePolicy("name-label"))
+	assert.ErrorIs(t, svc.DeletePolicy("unknown"), model.ErrPolicyNotFound)
+}
+
+func Test_service_CreateSnapshot(t *testing.T) {
+	repository := new(mocks.Repository)
+	rendered := `{"url":"/api/v1/charts/manifests/stable/aap-deploy/v0.0.1/e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0","manifests":[{"name":"service.yaml","content":"kind: Service"}],"notes":"Thanks"}`
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.1-e97cb507a10624290e0feeaca0726aae37c9ab90d12346c9fefa6b06f1f276c0").Return(rendered, nil)
+	isSnapshotKey := mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, "snapshot-") })
+	repository.On("SetWithExpiration", isSnapshotKey, mock.Anything, 72*time.Hour).Return(nil)
+	repository.On("HSet", "snapshot-index", mock.Anything, mock.Anything).Return(nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.CreateSnapshot("stable", "aap-deploy", "v0.0.1", model.SnapshotRequest{
+		Name:      "incident 42",
+		Author:    "oncall",
+		Values:    `{"ingress": false}`,
+		ExpiresIn: "72h",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, actual.ID, 16)
+	assert.Equal(t, "/api/v1/snapshots/"+actual.ID, actual.URL)
+	assert.Equal(t, "incident 42", actual.Name)
+	assert.Equal(t, "oncall", actual.Author)
+	assert.Equal(t, `{"ingress": false}`, actual.Values)
+	assert.Equal(t, []model.Manifest{{Name: "service.yaml", Content: "kind: Service"}}, actual.Manifests)
+	assert.Equal(t, "Thanks", actual.Notes)
+	assert.Equal(t, actual.CreatedAt.Add(72*time.Hour), *actual.ExpiresAt)
+
+	lastCall := repository.Calls[len(repository.Calls)-1]
+	assert.Equal(t, "HSet", lastCall.Method)
+	assert.Equal(t, actual.ID, lastCall.Arguments.String(1))
+
+	var stored model.Snapshot
+	_ = json.Unmarshal([]byte(lastCall.Arguments.String(2)), &stored)
+	assert.Equal(t, actual.ID, stored.ID)
+	assert.Equal(t, "incident 42", stored.Name)
+	assert.Nil(t, stored.Manifests)
+
+	_, err = svc.CreateSnapshot("stable", "aap-deploy", "v0.0.1", model.SnapshotRequest{Values: `{"ingress": false}`})
+	assert.ErrorIs(t, err, model.ErrInvalidSnapshot)
+
+	_, err = svc.CreateSnapshot("stable", "aap-deploy", "v0.0.1", model.SnapshotRequest{Name: "incident 42", ExpiresIn: "3 days"})
+	assert.ErrorIs(t, err, model.ErrInvalidSnapshot)
+}
+
+func Test_service_RerenderSnapshot(t *testing.T) {
+	repository := new(mocks.Repository)
+	parent := `{"id":"0123456789abcdef","name":"before the upgrade","author":"oncall","repo":"stable","chart":"aap-deploy","version":"v0.0.1","values":"{\"ingress\": false}","options":{},"url":"/api/v1/snapshots/0123456789abcdef","created_at":"2023-01-01T00:00:00Z"}`
+	repository.On("Get", "snapshot-0123456789abcdef").Return(parent, nil)
+	repository.On("Get", "manifests-stable-aap-deploy-v0.0.2-2b7ab404ee2e491975df77e2fde73c831af86fd3e9321fc32fb51339a372d203").Return(`{"manifests":[{"name":"service.yaml","content":"kind: Service"}]}`, nil)
+	repository.On("SetWithExpiration", mock.Anything, mock.Anything, time.Duration(0)).Return(nil)
+	repository.On("HSet", "snapshot-index", mock.Anything, mock.Anything).Return(nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.RerenderSnapshot("0123456789abcdef", model.SnapshotRerenderRequest{Version: "v0.0.2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", actual.ParentID)
+	assert.Equal(t, "before the upgrade", actual.Name)
+	assert.Equal(t, "v0.0.2", actual.Version)
+	assert.Equal(t, `{"ingress": false}`, actual.Values)
+	assert.Nil(t, actual.ExpiresAt)
+	assert.Equal(t, []model.Manifest{{Name: "service.yaml", Content: "kind: Service"}}, actual.Manifests)
+
+	repository.On("Get", "snapshot-unknown").Return("", nil)
+	_, err = svc.RerenderSnapshot("unknown", model.SnapshotRerenderRequest{Version: "v0.0.2"})
+	assert.ErrorIs(t, err, model.ErrSnapshotNotFound)
+}
+
+func Test_service_GetSnapshots(t *testing.T) {
+	repository := new(mocks.Repository)
+	older := model.Snapshot{ID: "0123456789abcdef", Name: "incident 42", URL: "/api/v1/snapshots/0123456789abcdef", CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newer := model.Snapshot{ID: "1111111111111111", Name: "incident 43", URL: "/api/v1/snapshots/1111111111111111", CreatedAt: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)}
+	expiredAt := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	expired := model.Snapshot{ID: "fedcba9876543210", Name: "incident 41", URL: "/api/v1/snapshots/fedcba9876543210", CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), ExpiresAt: &expiredAt}
+	deleted := model.Snapshot{ID: "2222222222222222", Name: "incident 40", URL: "/api/v1/snapshots/2222222222222222", CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	stored := map[string]string{}
+	for _, snapshot := range []model.Snapshot{older, newer, expired, deleted} {
+		snapshotByte, _ := json.Marshal(snapshot)
+		stored[snapshot.ID] = string(snapshotByte)
+	}
+	stored["3333333333333333"] = `{"id":`
+	repository.On("HGetAll", "snapshot-index").Return(stored, nil)
+	repository.On("Exists", "snapshot-0123456789abcdef").Return(true, nil)
+	repository.On("Exists", "snapshot-1111111111111111").Return(true, nil)
+	repository.On("Exists", "snapshot-fedcba9876543210").Return(true, nil)
+	repository.On("Exists", "snapshot-2222222222222222").Return(false, nil)
+	repository.On("HDel", "snapshot-index", mock.Anything).Return(nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.GetSnapshots()
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Snapshot{newer, older}, actual)
+	repository.AssertCalled(t, "HDel", "snapshot-index", "fedcba9876543210")
+	repository.AssertCalled(t, "HDel", "snapshot-index", "2222222222222222")
+	repository.AssertNumberOfCalls(t, "HDel", 2)
+}
+
+func Test_service_AnalyzeTemplate_withoutKubeVersions(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "api-versions").Return("", nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	_, err := svc.AnalyzeTemplate(nil, "1.22")
+	assert.EqualError(t, err, "the Kubernetes API versions are not seeded")
+}
+
+func Test_service_GetSnapshot(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Get", "snapshot-0123456789abcdef").Return(`{"id":"0123456789abcdef","name":"incident 42","manifests":[{"name":"service.yaml","content":"kind: Service"}],"notes":"Thanks"}`, nil)
+	repository.On("Get", "snapshot-fedcba9876543210").Return(`{"id":"fedcba9876543210","name":"incident 41","expires_at":"2023-01-02T00:00:00Z"}`, nil)
+	repository.On("Get", "snapshot-unknown").Return("", nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	actual, err := svc.GetSnapshot("0123456789abcdef")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Snapshot{ID: "0123456789abcdef", Name: "incident 42", Manifests: []model.Manifest{{Name: "service.yaml", Content: "kind: Service"}}, Notes: "Thanks"}, actual)
+
+	manifests, err := svc.GetStringifiedSnapshot("0123456789abcdef", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "---\nkind: Service\n", manifests)
+
+	_, err = svc.GetSnapshot("fedcba9876543210")
+	assert.ErrorIs(t, err, model.ErrSnapshotNotFound)
+
+	_, err = svc.GetSnapshot("unknown")
+	assert.ErrorIs(t, err, model.ErrSnapshotNotFound)
+}
+
+func Test_service_DeleteSnapshot(t *testing.T) {
+	repository := new(mocks.Repository)
+	repository.On("Exists", "snapshot-0123456789abcdef").Return(true, nil)
+	repository.On("Exists", "snapshot-unknown").Return(false, nil)
+	repository.On("Delete", "snapshot-0123456789abcdef").Return(nil)
+	repository.On("HDel", "snapshot-index", mock.Anything).Return(nil)
+
+	svc := service.NewService(nil, repository, nil, nil, nil)
+	err := svc.DeleteSnapshot("0123456789abcdef")
+	assert.NoError(t, err)
+	repository.AssertCalled(t, "Delete", "snapshot-0123456789abcdef")
+	repository.AssertCalled(t, "HDel", "snapshot-index", "0123456789abcdef")
+
+	err = svc.DeleteSnapshot("unknown")
+	assert.ErrorIs(t, err, model.ErrSnapshotNotFound)
+	repository.AssertCalled(t, "HDel", "snapshot-index", "unknown")
+	repository.AssertNumberOfCalls(t, "Delete", 1)
+}
@@ -0,0 +1,261 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StatusValid   = "valid"
+	StatusInvalid = "invalid"
+	StatusSkipped = "skipped"
+)
+
+// validator checks manifests against the Kubernetes JSON schemas stored in a
+// local directory, laid out like kubeconform and kubernetes-json-schema:
+// <dir>/v1.27.0-standalone-strict/deployment-apps-v1.json.
+type validator struct {
+	schemaDir string
+
+	mu      sync.Mutex
+	schemas map[string]*gojsonschema.Schema
+}
+
+func New(schemaDir string) *validator {
+	return &validator{
+		schemaDir: schemaDir,
+		schemas:   map[string]*gojsonschema.Schema{},
+	}
+}
+
+// Validate validates every document of the manifests for the Kubernetes
+// version. Custom resources are validated against the CRDs found in crds and
+// in the manifests themselves.
+func (v *validator) Validate(manifests []model.Manifest, crds []model.Manifest, kubeVersion string) ([]model.ManifestValidationResult, error) {
+	crdSchemas := customResourceSchemas(append(append([]model.Manifest{}, crds...), manifests...))
+
+	var results []model.ManifestValidationResult
+	for _, m := range manifests {
+		for _, document := range analyzer.SplitDocuments(m.Content) {
+			var object map[string]interface{}
+			err := yaml.Unmarshal([]byte(document), &object)
+			if err != nil {
+				results = append(results, model.ManifestValidationResult{
+					Name:     m.Name,
+					Template: m.Template,
+					Status:   StatusInvalid,
+					Message:  fmt.Sprintf("cannot parse manifest: %s", err),
+				})
+				continue
+			}
+
+			if len(object) == 0 {
+				continue
+			}
+
+			results = append(results, v.validate(m, object, crdSchemas, kubeVersion))
+		}
+	}
+
+	return results, nil
+}
+
+func (v *validator) validate(m model.Manifest, object map[string]interface{}, crdSchemas map[model.GroupVersionKind]crdSchema, kubeVersion string) model.ManifestValidationResult {
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+
+	result := model.ManifestValidationResult{
+		Name:       m.Name,
+		Template:   m.Template,
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Resource:   name,
+	}
+
+	if apiVersion == "" || kind == "" {
+		result.Status = StatusInvalid
+		result.Message = "apiVersion and kind are required"
+		return result
+	}
+
+	gvk := kubeversion.NewGroupVersionKind(apiVersion, kind)
+	crd, ok := crdSchemas[gvk]
+	if ok && crd.err != nil {
+		result.Status = StatusSkipped
+		result.Message = crd.err.Error()
+		return result
+	}
+
+	schema := crd.schema
+	if !ok {
+		var err error
+		schema, err = v.schema(gvk, kubeVersion)
+		if err != nil {
+			result.Status = StatusSkipped
+			result.Message = err.Error()
+			return result
+		}
+	}
+
+	validation, err := schema.Validate(gojsonschema.NewGoLoader(object))
+	if err != nil {
+		result.Status = StatusInvalid
+		result.Message = err.Error()
+		return result
+	}
+
+	result.Status = StatusValid
+	for _, e := range validation.Errors() {
+		result.Status = StatusInvalid
+		result.Errors = append(result.Errors, model.ValidationError{
+			Field:   e.Field(),
+			Message: e.Description(),
+		})
+	}
+
+	return result
+}
+
+func (v *validator) schema(gvk model.GroupVersionKind, kubeVersion string) (*gojsonschema.Schema, error) {
+	for _, path := range v.schemaPaths(gvk, kubeVersion) {
+		v.mu.Lock()
+		schema, ok := v.schemas[path]
+		v.mu.Unlock()
+		if ok {
+			return schema, nil
+		}
+
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err = gojsonschema.NewSchema(gojsonschema.NewBytesLoader(content))
+		if err != nil {
+			return nil, fmt.Errorf("cannot load schema %s: %w", path, err)
+		}
+
+		v.mu.Lock()
+		v.schemas[path] = schema
+		v.mu.Unlock()
+		return schema, nil
+	}
+
+	return nil, fmt.Errorf("no schema found for %s %s in Kubernetes %s", gvk.APIVersion(), gvk.Kind, kubeVersion)
+}
+
+func (v *validator) schemaPaths(gvk model.GroupVersionKind, kubeVersion string) []string {
+	version := kubeVersion
+	if strings.Count(version, ".") == 1 {
+		version += ".0"
+	}
+
+	fileName := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		fileName += "-" + strings.Split(gvk.Group, ".")[0]
+	}
+	fileName += "-" + gvk.Version + ".json"
+
+	var paths []string
+	for _, dir := range []string{"v" + version + "-standalone-strict", "v" + version + "-standalone", "v" + version, kubeVersion} {
+		paths = append(paths, filepath.Join(v.schemaDir, dir, fileName))
+	}
+
+	return paths
+}
+
+// crdSchema is the compiled schema of a custom resource version, or the
+// reason its CRD schema cannot be compiled.
+type crdSchema struct {
+	schema *gojsonschema.Schema
+	err    error
+}
+
+// customResourceSchemas compiles the openAPIV3Schema of every version served
+// by the CustomResourceDefinitions found in the manifests. A schema that
+// cannot be compiled only skips the custom resources of that version.
+func customResourceSchemas(manifests []model.Manifest) map[model.GroupVersionKind]crdSchema {
+	schemas := map[model.GroupVersionKind]crdSchema{}
+	for _, m := range manifests {
+		for _, document := range analyzer.SplitDocuments(m.Content) {
+			var crd customResourceDefinition
+			if yaml.Unmarshal([]byte(document), &crd) != nil || crd.Kind != "CustomResourceDefinition" {
+				continue
+			}
+
+			for version, openAPISchema := range crd.schemas() {
+				gvk := model.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.Kind}
+				schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(openAPISchema))
+				if err != nil {
+					schemas[gvk] = crdSchema{err: fmt.Errorf("cannot load schema of CRD %s in %s: %w", crd.Metadata.Name, m.Name, err)}
+					continue
+				}
+
+				schemas[gvk] = crdSchema{schema: schema}
+			}
+		}
+	}
+
+	return schemas
+}
+
+type customResourceDefinition struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		// apiextensions.k8s.io/v1beta1 declares a single version and schema.
+		Version    string            `yaml:"version"`
+		Validation *crdValidation    `yaml:"validation"`
+		Versions   []crdVersionEntry `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+type crdVersionEntry struct {
+	Name   string         `yaml:"name"`
+	Schema *crdValidation `yaml:"schema"`
+}
+
+type crdValidation struct {
+	OpenAPIV3Schema map[string]interface{} `yaml:"openAPIV3Schema"`
+}
+
+func (c customResourceDefinition) schemas() map[string]map[string]interface{} {
+	schemas := map[string]map[string]interface{}{}
+	for _, v := range c.Spec.Versions {
+		switch {
+		case v.Schema != nil && v.Schema.OpenAPIV3Schema != nil:
+			schemas[v.Name] = v.Schema.OpenAPIV3Schema
+		case c.Spec.Validation != nil && c.Spec.Validation.OpenAPIV3Schema != nil:
+			schemas[v.Name] = c.Spec.Validation.OpenAPIV3Schema
+		}
+	}
+
+	if c.Spec.Version != "" && c.Spec.Validation != nil && c.Spec.Validation.OpenAPIV3Schema != nil {
+		if _, ok := schemas[c.Spec.Version]; !ok {
+			schemas[c.Spec.Version] = c.Spec.Validation.OpenAPIV3Schema
+		}
+	}
+
+	return schemas
+}
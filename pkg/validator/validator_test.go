@@ -0,0 +1,166 @@
+package validator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+const configMapSchema = `{
+	"type": "object",
+	"required": ["apiVersion", "kind", "metadata"],
+	"additionalProperties": false,
+	"properties": {
+		"apiVersion": {"type": "string"},
+		"kind": {"type": "string"},
+		"metadata": {
+			"type": "object",
+			"properties": {"name": {"type": "string"}}
+		},
+		"data": {
+			"type": "object",
+			"additionalProperties": {"type": "string"}
+		}
+	}
+}`
+
+const crontabCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              required: ["cronSpec"]
+              properties:
+                cronSpec:
+                  type: string
+                replicas:
+                  type: integer
+`
+
+func Test_validator_Validate(t *testing.T) {
+	schemaDir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(schemaDir, "v1.22.0-standalone-strict"), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(schemaDir, "v1.22.0-standalone-strict", "configmap-v1.json"), []byte(configMapSchema), 0644)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		manifests []model.Manifest
+		crds      []model.Manifest
+		want      []model.ManifestValidationResult
+	}{
+		{
+			name: "should accept a valid manifest",
+			manifests: []model.Manifest{
+				{Name: "configmap.yaml", Content: "# Source: app/templates/configmap.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  key: value\n"},
+			},
+			want: []model.ManifestValidationResult{
+				{Name: "configmap.yaml", APIVersion: "v1", Kind: "ConfigMap", Resource: "app", Status: "valid"},
+			},
+		},
+		{
+			name: "should report wrong types and unknown fields",
+			manifests: []model.Manifest{
+				{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: 3\nspec: {}\n"},
+			},
+			want: []model.ManifestValidationResult{
+				{
+					Name:       "configmap.yaml",
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Resource:   "app",
+					Status:     "invalid",
+					Errors: []model.ValidationError{
+						{Field: "(root)", Message: "Additional property spec is not allowed"},
+						{Field: "data.replicas", Message: "Invalid type. Expected: string, given: integer"},
+					},
+				},
+			},
+		},
+		{
+			name: "should skip resources without schema",
+			manifests: []model.Manifest{
+				{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n"},
+			},
+			want: []model.ManifestValidationResult{
+				{Name: "deployment.yaml", APIVersion: "apps/v1", Kind: "Deployment", Resource: "app", Status: "skipped", Message: "no schema found for apps/v1 Deployment in Kubernetes 1.22"},
+			},
+		},
+		{
+			name: "should validate custom resources against the chart CRDs",
+			crds: []model.Manifest{
+				{Name: "crontab.yaml", Template: "crds/crontab.yaml", Content: crontabCRD},
+			},
+			manifests: []model.Manifest{
+				{Name: "crontab.yaml", Content: "apiVersion: stable.example.com/v1\nkind: CronTab\nmetadata:\n  name: app\nspec:\n  replicas: \"1\"\n"},
+			},
+			want: []model.ManifestValidationResult{
+				{
+					Name:       "crontab.yaml",
+					APIVersion: "stable.example.com/v1",
+					Kind:       "CronTab",
+					Resource:   "app",
+					Status:     "invalid",
+					Errors: []model.ValidationError{
+						{Field: "spec", Message: "cronSpec is required"},
+						{Field: "spec.replicas", Message: "Invalid type. Expected: integer, given: string"},
+					},
+				},
+			},
+		},
+		{
+			name: "should skip the custom resources of a CRD whose schema cannot be loaded",
+			crds: []model.Manifest{
+				{Name: "backup.yaml", Template: "crds/backup.yaml", Content: "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: backups.acme.io\nspec:\n  group: acme.io\n  names:\n    kind: Backup\n  versions:\n    - name: v1\n      schema:\n        openAPIV3Schema:\n          type: notatype\n"},
+				{Name: "crontab.yaml", Template: "crds/crontab.yaml", Content: crontabCRD},
+			},
+			manifests: []model.Manifest{
+				{Name: "backup.yaml", Content: "apiVersion: acme.io/v1\nkind: Backup\nmetadata:\n  name: app\n"},
+				{Name: "crontab.yaml", Content: "apiVersion: stable.example.com/v1\nkind: CronTab\nmetadata:\n  name: app\nspec:\n  cronSpec: \"* * * * *\"\n"},
+			},
+			want: []model.ManifestValidationResult{
+				{
+					Name:       "backup.yaml",
+					APIVersion: "acme.io/v1",
+					Kind:       "Backup",
+					Resource:   "app",
+					Status:     "skipped",
+					Message:    "cannot load schema of CRD backups.acme.io in backup.yaml: has a primitive type that is NOT VALID -- given: /notatype/ Expected valid values are:[array boolean integer number null object string]",
+				},
+				{Name: "crontab.yaml", APIVersion: "stable.example.com/v1", Kind: "CronTab", Resource: "app", Status: "valid"},
+			},
+		},
+		{
+			name: "should report manifests without kind",
+			manifests: []model.Manifest{
+				{Name: "broken.yaml", Content: "metadata:\n  name: app\n"},
+			},
+			want: []model.ManifestValidationResult{
+				{Name: "broken.yaml", Resource: "app", Status: "invalid", Message: "apiVersion and kind are required"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := validator.New(schemaDir).Validate(tt.manifests, tt.crds, "1.22")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
@@ -11,7 +11,8 @@ async function fetchCharts(repoName) {
 }
 
 async function fetchChart(repoName, chartName, chartVersion, kubeVersion) {
-    return await axios.get(baseURL + '/api/v1/charts/' + repoName + '/' + chartName + '/' + chartVersion + '?kube-version=' + kubeVersion)
+    const query = kubeVersion ? '?kube-version=' + kubeVersion : ''
+    return await axios.get(baseURL + '/api/v1/charts/' + repoName + '/' + chartName + '/' + chartVersion + query)
 }
 
 async function renderManifest(repoName, chartName, chartVersion, values) {